@@ -1,12 +1,16 @@
 package app
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/not-empty/grit-microframework-go/app/config"
 	"github.com/not-empty/grit-microframework-go/app/database"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
 	"github.com/not-empty/grit-microframework-go/app/router"
 
 	_ "github.com/not-empty/grit-microframework-go/app/router/domains"
@@ -23,12 +27,40 @@ func Bootstrap() {
 
 	dbConfig := database.LoadDatabaseConfig()
 	db := database.Init(dbConfig)
+	repository.SetReadReplicas(database.InitReplicas(dbConfig)...)
+	repository.SetReadYourWrites(time.Duration(config.AppConfig.DBReadYourWrites) * time.Millisecond)
+	helper.SetCursorSecret(config.AppConfig.CursorSecret)
 	router.RegisterRoutes(db)
 }
 
 func StartServer() {
 	port := config.AppConfig.AppPort
 
+	if interval := config.AppConfig.PurgeInterval; interval > 0 {
+		go enforceRetention(time.Duration(interval)*time.Second, config.AppConfig.PurgeBatchSize)
+	}
+
 	log.Printf("Server starting on :%s", port)
 	log.Fatal(http.ListenAndServe(":"+port, http.DefaultServeMux))
 }
+
+func Purge(batch int) {
+	purged, err := repository.PurgeAll(context.Background(), time.Now(), batch)
+	for name, n := range purged {
+		if n > 0 {
+			log.Printf("Purged %d %s records", n, name)
+		}
+	}
+	if err != nil {
+		log.Printf("Purge error: %v", err)
+	}
+}
+
+func enforceRetention(interval time.Duration, batch int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		Purge(batch)
+	}
+}
@@ -14,6 +14,8 @@ type Config struct {
 	AppNoAuth bool
 	AppPort   string
 
+	AppStrictQuery bool
+
 	DBDriver  string
 	DBHost    string
 	DBMaxConn int
@@ -21,8 +23,14 @@ type Config struct {
 	DBName    string
 	DBPass    string
 	DBPort    string
+	DBSchema  string
+	DBSSLMode string
 	DBUser    string
 
+	DBQueryTimeout   int
+	DBReadYourWrites int
+	DBReplicas       string
+
 	DBHostTest string
 	DBNameTest string
 	DBPassTest string
@@ -32,6 +40,11 @@ type Config struct {
 	JwtAppSecret string
 	JwtExpire    int64
 	JwtRenew     int64
+
+	CursorSecret string
+
+	PurgeBatchSize int
+	PurgeInterval  int
 }
 
 func LoadConfig() *Config {
@@ -41,6 +54,8 @@ func LoadConfig() *Config {
 		AppNoAuth: GetEnvBool("APP_NO_AUTH", false),
 		AppPort:   GetEnvStr("APP_PORT", "8001"),
 
+		AppStrictQuery: GetEnvBool("APP_STRICT_QUERY", false),
+
 		DBDriver:  GetEnvStr("DB_DRIVER", "mysql"),
 		DBHost:    GetEnvStr("DB_HOST", "grit-mysql"),
 		DBMaxConn: GetEnvInt("DB_MAX_CONN", 100),
@@ -48,8 +63,14 @@ func LoadConfig() *Config {
 		DBName:    GetEnvStr("DB_NAME", "grit"),
 		DBPass:    GetEnvStr("DB_PASS", ""),
 		DBPort:    GetEnvStr("DB_PORT", "3306"),
+		DBSchema:  GetEnvStr("DB_SCHEMA", ""),
+		DBSSLMode: GetEnvStr("DB_SSL_MODE", "disable"),
 		DBUser:    GetEnvStr("DB_USER", "root"),
 
+		DBQueryTimeout:   GetEnvInt("DB_QUERY_TIMEOUT", 30000),
+		DBReadYourWrites: GetEnvInt("DB_READ_YOUR_WRITES", 0),
+		DBReplicas:       GetEnvStr("DB_REPLICAS", ""),
+
 		DBHostTest: GetEnvStr("DB_HOST_TEST", "grit-mysql"),
 		DBNameTest: GetEnvStr("DB_NAME_TEST", "grit"),
 		DBPassTest: GetEnvStr("DB_PASS_TEST", ""),
@@ -59,6 +80,11 @@ func LoadConfig() *Config {
 		JwtAppSecret: GetEnvStr("JWT_APP_SECRET", "secret"),
 		JwtExpire:    GetEnvInt64("JWT_EXPIRE", 9000),
 		JwtRenew:     GetEnvInt64("JWT_RENEW", 6000),
+
+		CursorSecret: GetEnvStr("CURSOR_SECRET", ""),
+
+		PurgeBatchSize: GetEnvInt("PURGE_BATCH_SIZE", 500),
+		PurgeInterval:  GetEnvInt("PURGE_INTERVAL", 0),
 	}
 
 	AppConfig = c
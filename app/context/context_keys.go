@@ -3,6 +3,7 @@ package context
 type JwtTokenInfo struct {
 	Token   string
 	Expires string
+	Context string
 }
 
 type contextKey string
@@ -1,8 +1,15 @@
 package controller
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/not-empty/grit-microframework-go/app/helper"
@@ -10,11 +17,26 @@ import (
 	"github.com/not-empty/ulid-go-lib"
 )
 
+const (
+	maxBulkMutation    = 500
+	defaultMaxBulkIDs  = 500
+	maxAggregateGroups = 1000
+)
+
 type BaseController[T repository.BaseModel] struct {
-	Repo    repository.RepositoryInterface[T]
-	Prefix  string
-	SetPK   func(m T, id string)
-	ULIDGen ulid.Generator
+	Repo           repository.RepositoryInterface[T]
+	Prefix         string
+	SetPK          func(m T, id string)
+	SetKey         func(m T, key []interface{})
+	ULIDGen        ulid.Generator
+	IDStrategy     helper.IDStrategy
+	QueryTimeout   time.Duration
+	RequireIfMatch bool
+	MaxOffset      int
+	StrictQuery    bool
+	MaxFilters     int
+	MaxInValues    int
+	MaxBulkIDs     int
 }
 
 func NewBaseController[T repository.BaseModel](repo repository.RepositoryInterface[T], prefix string, setPK func(m T, id string)) *BaseController[T] {
@@ -44,31 +66,76 @@ func (bc *BaseController[T]) Add(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := m.PrimaryKeyValue().(string)
-	if helper.IsEmptyValue(id) {
-		var err error
-		id, err = bc.ULIDGen.Generate(0)
-		if err != nil {
-			helper.JSONError(w, http.StatusInternalServerError, "ULID error", err)
-			return
-		}
-		bc.SetPK(m, id)
+	if err := bc.generateKey(m); err != nil {
+		writeKeyError(w, "ULID error", err)
+		return
 	}
 
-	now := time.Now()
-	if c, ok := any(m).(repository.Creatable); ok {
-		c.SetCreatedAt(now)
+	setTimestamps(m, time.Now())
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	if err := bc.Repo.Add(ctx, m); err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Insert error", err)
+		return
 	}
-	if u, ok := any(m).(repository.Updatable); ok {
-		u.SetUpdatedAt(now)
+
+	if helper.PrefersRepresentation(r) {
+		bc.writeRepresentation(ctx, w, r, http.StatusCreated, m)
+		return
+	}
+	helper.JSONResponse(w, http.StatusCreated, map[string]any{"id": keyResponse(m)})
+}
+
+func (bc *BaseController[T]) Aggregate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
+
+	m := bc.Repo.New()
+	allowed := repository.AggregateColumns(m)
+	query := r.URL.Query()
 
-	if err := bc.Repo.Add(m); err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Insert error", err)
+	aggs, err := helper.ParseAggregates(query["metric"], allowed, m.Schema())
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid metric", err)
+		return
+	}
+	groupBy, err := helper.ParseGroupBy(query.Get("group_by"), allowed)
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid group_by", err)
 		return
 	}
+	// A dropped filter would widen every total, so bad filters are refused
+	// here even when the domain is not strict.
+	filters, err := helper.ParseFilters(query["filter"], bc.queryRules().Filters, m.Schema())
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
 
-	helper.JSONResponse(w, http.StatusCreated, map[string]string{"id": id})
+	// One group past the cap is read to tell a full answer from a cut one.
+	rows, err := bc.Repo.Aggregate(ctx, aggs, groupBy, filters, maxAggregateGroups+1)
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Aggregate error", err)
+		return
+	}
+	if len(rows) > maxAggregateGroups {
+		rows = rows[:maxAggregateGroups]
+		w.Header().Set("X-Truncated", "true")
+	}
+	if rows == nil {
+		rows = []map[string]any{}
+	}
+	helper.JSONResponse(w, http.StatusOK, rows)
 }
 
 func (bc *BaseController[T]) Bulk(w http.ResponseWriter, r *http.Request) {
@@ -76,29 +143,66 @@ func (bc *BaseController[T]) Bulk(w http.ResponseWriter, r *http.Request) {
 		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
 
 	var input struct {
-		IDs []string `json:"ids"`
+		IDs []any `json:"ids"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || len(input.IDs) == 0 {
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&input); err != nil || len(input.IDs) == 0 {
 		helper.JSONError(w, http.StatusBadRequest, "Invalid or empty Ids list", err)
 		return
 	}
+	if len(input.IDs) > bc.maxBulkIDs() {
+		helper.JSONErrorSimple(w, http.StatusBadRequest,
+			fmt.Sprintf("Ids list must contain between 1 and %d items", bc.maxBulkIDs()))
+		return
+	}
+	ids := make([]string, len(input.IDs))
+	for i, id := range input.IDs {
+		ids[i] = helper.KeyString(id)
+	}
 
-	orderBy, order := helper.GetOrderParams(r, "id")
+	orderBy, order := helper.GetOrderParams(r, bc.keys()[0])
 	limit, pageCursor, err := helper.GetPaginationParams(r)
 	if err != nil {
 		helper.JSONError(w, http.StatusBadRequest, "Invalid Page Cursor", err)
 		return
 	}
-	fields := helper.GetFieldsParamList(r, bc.Repo.New().Columns(), orderBy)
+	offset, ok := bc.pageOffset(w, r, limit, pageCursor)
+	if !ok {
+		return
+	}
+	fields := bc.listFields(r, orderBy)
+	inc, ok := bc.includes(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
 
-	list, err := bc.Repo.Bulk(input.IDs, limit, pageCursor, orderBy, order, fields)
+	list, err := bc.Repo.Bulk(ctx, ids, limit, offset, pageCursor, orderBy, order, inc.queryFields(fields))
 	if err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Bulk error", err)
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Bulk error", err)
 		return
 	}
-	helper.JSONResponse(w, http.StatusOK, helper.FilterList(list, fields))
+	if err := bc.loadIncludes(ctx, list, inc); err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Include error", err)
+		return
+	}
+	if !bc.setTotal(ctx, w, r, func(ctx context.Context) (int64, error) {
+		return bc.Repo.BulkCount(ctx, ids)
+	}) {
+		return
+	}
+	bc.setPageCursors(w, r, func() (string, string) {
+		return bc.Repo.BulkPageCursors(list, ids, limit, pageCursor, orderBy, order)
+	})
+	helper.JSONResponse(w, http.StatusOK, helper.FilterList(list, inc.responseFields(fields)))
 }
 
 func (bc *BaseController[T]) BulkAdd(w http.ResponseWriter, r *http.Request) {
@@ -120,42 +224,203 @@ func (bc *BaseController[T]) BulkAdd(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var generatedIDs []string
 	now := time.Now()
-
 	for _, m := range items {
 		helper.SanitizeModel(m)
 		if err := helper.ValidatePayload(w, m); err != nil {
 			return
 		}
 
-		id := m.PrimaryKeyValue().(string)
-		if helper.IsEmptyValue(id) {
-			var err error
-			id, err = bc.ULIDGen.Generate(0)
-			if err != nil {
-				helper.JSONError(w, http.StatusInternalServerError, "ULID generation failed", err)
-				return
-			}
-			bc.SetPK(m, id)
+		if err := bc.generateKey(m); err != nil {
+			writeKeyError(w, "ULID generation failed", err)
+			return
 		}
 
-		generatedIDs = append(generatedIDs, id)
-		if c, ok := any(m).(repository.Creatable); ok {
-			c.SetCreatedAt(now)
+		setTimestamps(m, now)
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	if err := bc.Repo.BulkAdd(ctx, items); err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Bulk insert failed", err)
+		return
+	}
+
+	if helper.PrefersRepresentation(r) {
+		bc.writeRepresentations(ctx, w, r, http.StatusCreated, items)
+		return
+	}
+
+	// Auto-increment ids are only known once the rows are inserted.
+	ids := make([]any, len(items))
+	for i, m := range items {
+		ids[i] = keyResponse(m)
+	}
+	helper.JSONResponse(w, http.StatusCreated, map[string][]any{
+		"ids": ids,
+	})
+}
+
+func (bc *BaseController[T]) BulkDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	input, ok := decodeBulkMutation(w, r)
+	if !ok {
+		return
+	}
+	if !validBulkIDs(w, input.IDs) {
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+	if reason := r.URL.Query().Get("reason"); reason != "" {
+		ctx = repository.WithDeleteReason(ctx, reason)
+	}
+
+	bc.runBulk(ctx, w, input.IDs, "deleted", "Bulk delete error", bc.Repo.BulkDelete)
+}
+
+func (bc *BaseController[T]) BulkEdit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	input, ok := decodeBulkMutation(w, r)
+	if !ok {
+		return
+	}
+
+	for _, key := range bc.keys() {
+		delete(input.Data, key)
+	}
+	if len(input.Data) == 0 {
+		helper.JSONErrorSimple(w, http.StatusBadRequest, "Invalid data")
+		return
+	}
+	cols, vals := bc.patchColumns(input.Data)
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	if len(input.Filter) == 0 {
+		if !validBulkIDs(w, input.IDs) {
+			return
 		}
-		if u, ok := any(m).(repository.Updatable); ok {
-			u.SetUpdatedAt(now)
+		bc.runBulk(ctx, w, input.IDs, "updated", "Bulk edit error", func(ctx context.Context, ids []interface{}) ([]repository.BulkResult, error) {
+			return bc.Repo.BulkEdit(ctx, ids, cols, vals)
+		})
+		return
+	}
+
+	if len(input.IDs) > 0 {
+		helper.JSONErrorSimple(w, http.StatusBadRequest, "Use either ids or filter")
+		return
+	}
+
+	m := bc.Repo.New()
+	filters, err := helper.ParseFilters(input.Filter, bc.queryRules().Filters, m.Schema())
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+
+	affected, err := bc.Repo.BulkEditWhere(ctx, filters, cols, vals)
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Bulk edit error", err)
+		return
+	}
+
+	helper.JSONResponse(w, http.StatusOK, map[string]any{"affected": affected})
+}
+
+func (bc *BaseController[T]) BulkUndelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	input, ok := decodeBulkMutation(w, r)
+	if !ok {
+		return
+	}
+	if !validBulkIDs(w, input.IDs) {
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	bc.runBulk(ctx, w, input.IDs, "undeleted", "Bulk undelete error", bc.Repo.BulkUndelete)
+}
+
+func (bc *BaseController[T]) BulkUpsert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var items []T
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid JSON payload (must be an array)", err)
+		return
+	}
+
+	count := len(items)
+	if count == 0 || count > 25 {
+		helper.JSONErrorSimple(w, http.StatusBadRequest,
+			"Payload must contain between 1 and 25 items")
+		return
+	}
+
+	now := time.Now()
+	for _, m := range items {
+		helper.SanitizeModel(m)
+		if err := helper.ValidatePayload(w, m); err != nil {
+			return
 		}
+
+		if err := bc.generateKey(m); err != nil {
+			writeKeyError(w, "ULID generation failed", err)
+			return
+		}
+		setTimestamps(m, now)
 	}
 
-	if err := bc.Repo.BulkAdd(items); err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Bulk insert failed", err)
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	created, err := bc.Repo.BulkUpsert(ctx, items)
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Bulk upsert failed", err)
+		return
+	}
+
+	// 201 only when every record was created; "created" tells them apart.
+	status := http.StatusCreated
+	for _, c := range created {
+		if !c {
+			status = http.StatusOK
+		}
+	}
+
+	if helper.PrefersRepresentation(r) {
+		bc.writeRepresentations(ctx, w, r, status, items)
 		return
 	}
 
-	helper.JSONResponse(w, http.StatusCreated, map[string][]string{
-		"ids": generatedIDs,
+	ids := make([]any, len(items))
+	for i, m := range items {
+		ids[i] = keyResponse(m)
+	}
+	helper.JSONResponse(w, status, map[string]any{
+		"ids":     ids,
+		"created": created,
 	})
 }
 
@@ -164,20 +429,27 @@ func (bc *BaseController[T]) DeadDetail(w http.ResponseWriter, r *http.Request)
 		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
 
-	id, err := helper.ExtractID(r.URL.Path, bc.Prefix+"/dead_detail/")
-	if err != nil {
-		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+	key, ok := bc.pathKey(w, r, "/dead_detail/")
+	if !ok {
 		return
 	}
 
 	fields := helper.GetFieldsParamOne(r, bc.Repo.New().Columns())
-	m, err := bc.Repo.DeadDetail(id, fields)
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	m, err := bc.Repo.DeadDetail(ctx, keyParam(key), bc.versionFields(fields))
 	if err != nil {
-		helper.JSONError(w, http.StatusNotFound, "Detail error", err)
+		writeRepoError(ctx, w, http.StatusNotFound, "Detail error", err)
 		return
 	}
 
+	bc.setETag(w, m)
 	helper.JSONResponse(w, http.StatusOK, helper.FilterJSON(m, fields))
 }
 
@@ -186,22 +458,43 @@ func (bc *BaseController[T]) DeadList(w http.ResponseWriter, r *http.Request) {
 		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
 
-	orderBy, order := helper.GetOrderParams(r, "id")
+	orderBy, order := helper.GetOrderParams(r, bc.keys()[0])
 	limit, pageCursor, err := helper.GetPaginationParams(r)
 	if err != nil {
 		helper.JSONError(w, http.StatusBadRequest, "Invalid Page Cursor", err)
 		return
 	}
-	fields := helper.GetFieldsParamList(r, bc.Repo.New().Columns(), orderBy)
-	filters := helper.GetFilters(r, bc.Repo.New().Columns())
+	offset, ok := bc.pageOffset(w, r, limit, pageCursor)
+	if !ok {
+		return
+	}
+	fields := bc.listFields(r, orderBy)
+	filters, ok := bc.searchFilters(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
 
-	list, err := bc.Repo.DeadList(limit, pageCursor, orderBy, order, fields, filters)
+	list, err := bc.Repo.DeadList(ctx, limit, offset, pageCursor, orderBy, order, fields, filters)
 	if err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "List error", err)
+		writeRepoError(ctx, w, http.StatusInternalServerError, "List error", err)
+		return
+	}
+	if !bc.setTotal(ctx, w, r, func(ctx context.Context) (int64, error) {
+		return bc.Repo.DeadCount(ctx, filters)
+	}) {
 		return
 	}
 
+	bc.setPageCursors(w, r, func() (string, string) {
+		return bc.Repo.PageCursors(list, limit, pageCursor, orderBy, order, filters)
+	})
 	helper.JSONResponse(w, http.StatusOK, helper.FilterList(list, fields))
 }
 
@@ -211,17 +504,33 @@ func (bc *BaseController[T]) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := helper.ExtractID(r.URL.Path, bc.Prefix+"/delete/")
-	if err != nil {
-		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+	key, ok := bc.pathKey(w, r, "/delete/")
+	if !ok {
+		return
+	}
+
+	etag, ok := bc.ifMatch(w, r)
+	if !ok {
 		return
 	}
 
 	m := bc.Repo.New()
-	bc.SetPK(m, id)
+	bc.setKey(m, key)
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+	if reason := r.URL.Query().Get("reason"); reason != "" {
+		ctx = repository.WithDeleteReason(ctx, reason)
+	}
 
-	if err := bc.Repo.Delete(m); err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Delete error", err)
+	var err error
+	if etag != "" {
+		err = bc.Repo.DeleteIfMatch(ctx, m, etag)
+	} else {
+		err = bc.Repo.Delete(ctx, m)
+	}
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Delete error", err)
 		return
 	}
 
@@ -233,21 +542,36 @@ func (bc *BaseController[T]) Detail(w http.ResponseWriter, r *http.Request) {
 		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
 
-	id, err := helper.ExtractID(r.URL.Path, bc.Prefix+"/detail/")
-	if err != nil {
-		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+	key, ok := bc.pathKey(w, r, "/detail/")
+	if !ok {
 		return
 	}
 
 	fields := helper.GetFieldsParamOne(r, bc.Repo.New().Columns())
-	m, err := bc.Repo.Detail(id, fields)
+	inc, ok := bc.includes(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	m, err := bc.Repo.Detail(ctx, keyParam(key), bc.versionFields(inc.queryFields(fields)))
 	if err != nil {
-		helper.JSONError(w, http.StatusNotFound, "Detail error", err)
+		writeRepoError(ctx, w, http.StatusNotFound, "Detail error", err)
+		return
+	}
+	if err := bc.loadIncludes(ctx, []map[string]any{m}, inc); err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Include error", err)
 		return
 	}
 
-	helper.JSONResponse(w, http.StatusOK, helper.FilterJSON(m, fields))
+	bc.setETag(w, m)
+	helper.JSONResponse(w, http.StatusOK, helper.FilterJSON(m, inc.responseFields(fields)))
 }
 
 func (bc *BaseController[T]) Edit(w http.ResponseWriter, r *http.Request) {
@@ -256,9 +580,13 @@ func (bc *BaseController[T]) Edit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := helper.ExtractID(r.URL.Path, bc.Prefix+"/edit/")
-	if err != nil {
-		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+	key, ok := bc.pathKey(w, r, "/edit/")
+	if !ok {
+		return
+	}
+
+	etag, ok := bc.ifMatch(w, r)
+	if !ok {
 		return
 	}
 
@@ -268,9 +596,19 @@ func (bc *BaseController[T]) Edit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	fetched, err := bc.Repo.Detail(id, bc.Repo.New().Columns())
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	fetched, err := bc.Repo.Detail(repository.WithPrimary(ctx), keyParam(key), bc.Repo.New().Columns())
 	if err != nil {
-		helper.JSONError(w, http.StatusNotFound, "Not found", err)
+		writeRepoError(ctx, w, http.StatusNotFound, "Not found", err)
+		return
+	}
+
+	versionCol := repository.VersionColumn(bc.Repo.New())
+	currentETag := repository.RecordETag(bc.Repo.New(), fetched)
+	if etag != "" && versionCol != "" && !helper.MatchETag(etag, currentETag) {
+		helper.JSONErrorSimple(w, http.StatusPreconditionFailed, "Precondition failed")
 		return
 	}
 
@@ -280,29 +618,26 @@ func (bc *BaseController[T]) Edit(w http.ResponseWriter, r *http.Request) {
 
 	helper.SanitizeModel(fetched)
 
-	allCols := bc.Repo.New().Columns()
-	var updateCols []string
-	var updateVals []interface{}
-	for _, col := range allCols {
-		if _, exists := patchData[col]; exists {
-			updateCols = append(updateCols, col)
-			updateVals = append(updateVals, fetched[col])
-		}
-	}
-
-	if _, ok := any(bc.Repo.New()).(repository.Updatable); ok {
-		updateCols = append(updateCols, "updated_at")
-		updateVals = append(updateVals, time.Now())
-	}
+	updateCols, updateVals := bc.patchColumns(patchData)
 
 	m := bc.Repo.New()
-	bc.SetPK(m, id)
+	bc.setKey(m, key)
+	pkVal := keyParam(repository.PrimaryKeyValues(m))
 
-	if err := bc.Repo.Edit(m.TableName(), m.PrimaryKey(), m.PrimaryKeyValue(), updateCols, updateVals); err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Edit error", err)
+	if etag != "" && versionCol != "" {
+		err = bc.Repo.EditIfMatch(ctx, m.TableName(), m.PrimaryKey(), pkVal, updateCols, updateVals, currentETag)
+	} else {
+		err = bc.Repo.Edit(ctx, m.TableName(), m.PrimaryKey(), pkVal, updateCols, updateVals)
+	}
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Edit error", err)
 		return
 	}
 
+	if helper.PrefersRepresentation(r) {
+		bc.writeRepresentation(ctx, w, r, http.StatusOK, m)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -311,23 +646,52 @@ func (bc *BaseController[T]) List(w http.ResponseWriter, r *http.Request) {
 		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
 
-	orderBy, order := helper.GetOrderParams(r, "id")
+	orderBy, order := helper.GetOrderParams(r, bc.keys()[0])
 	limit, pageCursor, err := helper.GetPaginationParams(r)
 	if err != nil {
 		helper.JSONError(w, http.StatusBadRequest, "Invalid Page Cursor", err)
 		return
 	}
-	fields := helper.GetFieldsParamList(r, bc.Repo.New().Columns(), orderBy)
-	filters := helper.GetFilters(r, bc.Repo.New().Columns())
+	offset, ok := bc.pageOffset(w, r, limit, pageCursor)
+	if !ok {
+		return
+	}
+	fields := bc.listFields(r, orderBy)
+	filters, ok := bc.searchFilters(w, r)
+	if !ok {
+		return
+	}
+	inc, ok := bc.includes(w, r)
+	if !ok {
+		return
+	}
 
-	list, err := bc.Repo.List(limit, pageCursor, orderBy, order, fields, filters)
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	list, err := bc.Repo.List(ctx, limit, offset, pageCursor, orderBy, order, inc.queryFields(fields), filters)
 	if err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "List error", err)
+		writeRepoError(ctx, w, http.StatusInternalServerError, "List error", err)
+		return
+	}
+	if err := bc.loadIncludes(ctx, list, inc); err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Include error", err)
+		return
+	}
+	if !bc.setTotal(ctx, w, r, func(ctx context.Context) (int64, error) {
+		return bc.Repo.Count(ctx, filters)
+	}) {
 		return
 	}
 
-	helper.JSONResponse(w, http.StatusOK, helper.FilterList(list, fields))
+	bc.setPageCursors(w, r, func() (string, string) {
+		return bc.Repo.PageCursors(list, limit, pageCursor, orderBy, order, filters)
+	})
+	helper.JSONResponse(w, http.StatusOK, helper.FilterList(list, inc.responseFields(fields)))
 }
 
 func (bc *BaseController[T]) ListOne(w http.ResponseWriter, r *http.Request) {
@@ -335,14 +699,23 @@ func (bc *BaseController[T]) ListOne(w http.ResponseWriter, r *http.Request) {
 		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	if !bc.strictQuery(w, r) {
+		return
+	}
 
-	orderBy, order := helper.GetOrderParams(r, "id")
+	orderBy, order := helper.GetOrderParams(r, bc.keys()[0])
 	fields := helper.GetFieldsParamOne(r, bc.Repo.New().Columns())
-	filters := helper.GetFilters(r, bc.Repo.New().Columns())
+	filters, ok := bc.searchFilters(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
 
-	result, err := bc.Repo.ListOne(orderBy, order, fields, filters)
+	result, err := bc.Repo.ListOne(ctx, orderBy, order, fields, filters)
 	if err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "List one error", err)
+		writeRepoError(ctx, w, http.StatusInternalServerError, "List one error", err)
 		return
 	}
 
@@ -387,9 +760,12 @@ func (bc *BaseController[T]) Raw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := bc.Repo.Raw(sqlText, input.Params)
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	results, err := bc.Repo.Raw(ctx, sqlText, input.Params)
 	if err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Raw execution failed", err)
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Raw execution failed", err)
 		return
 	}
 
@@ -402,19 +778,596 @@ func (bc *BaseController[T]) Undelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := helper.ExtractID(r.URL.Path, bc.Prefix+"/undelete/")
+	key, ok := bc.pathKey(w, r, "/undelete/")
+	if !ok {
+		return
+	}
+
+	etag, ok := bc.ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	m := bc.Repo.New()
+	bc.setKey(m, key)
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	var err error
+	if etag != "" {
+		err = bc.Repo.UndeleteIfMatch(ctx, m, etag)
+	} else {
+		err = bc.Repo.Undelete(ctx, m)
+	}
 	if err != nil {
-		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Undelete error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (bc *BaseController[T]) Purge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	key, ok := bc.pathKey(w, r, "/purge/")
+	if !ok {
 		return
 	}
 
 	m := bc.Repo.New()
-	bc.SetPK(m, id)
+	bc.setKey(m, key)
 
-	if err := bc.Repo.Undelete(m); err != nil {
-		helper.JSONError(w, http.StatusInternalServerError, "Undelete error", err)
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	if err := bc.Repo.Purge(ctx, m); err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Purge error", err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (bc *BaseController[T]) Upsert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		helper.JSONErrorSimple(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	m := bc.Repo.New()
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid JSON", err)
+		return
+	}
+
+	helper.SanitizeModel(m)
+	if err := helper.ValidatePayload(w, m); err != nil {
+		return
+	}
+
+	if err := bc.generateKey(m); err != nil {
+		writeKeyError(w, "ULID error", err)
+		return
+	}
+	setTimestamps(m, time.Now())
+
+	ctx, cancel := bc.queryContext(r)
+	defer cancel()
+
+	created, err := bc.Repo.Upsert(ctx, m)
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Upsert error", err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	if helper.PrefersRepresentation(r) {
+		bc.writeRepresentation(ctx, w, r, status, m)
+		return
+	}
+
+	helper.JSONResponse(w, status, map[string]any{"id": keyResponse(m)})
+}
+
+func (bc *BaseController[T]) queryContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return helper.QueryContext(r.Context(), bc.QueryTimeout)
+}
+
+func (bc *BaseController[T]) keys() []string {
+	return repository.PrimaryKeys(bc.Repo.New())
+}
+
+func (bc *BaseController[T]) pathKey(w http.ResponseWriter, r *http.Request, action string) ([]interface{}, bool) {
+	id, err := helper.ExtractID(r.URL.Path, bc.Prefix+action)
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+		return nil, false
+	}
+
+	key, err := bc.parseKey(id)
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid Id", err)
+		return nil, false
+	}
+	return key, true
+}
+
+func (bc *BaseController[T]) parseKey(id string) ([]interface{}, error) {
+	keys := bc.keys()
+	if bc.IDStrategy != nil && len(keys) == 1 {
+		if err := bc.IDStrategy.ValidateID(id); err != nil {
+			return nil, err
+		}
+	}
+	return helper.ParseKey(id, keys, bc.Repo.New().Schema())
+}
+
+func (bc *BaseController[T]) patchColumns(patch map[string]interface{}) ([]string, []interface{}) {
+	m := bc.Repo.New()
+	_, versioned := any(m).(repository.Versionable)
+	versionCol := repository.VersionColumn(m)
+
+	var cols []string
+	var vals []interface{}
+	for _, col := range m.Columns() {
+		if versioned && col == versionCol {
+			continue
+		}
+		if val, exists := patch[col]; exists {
+			cols = append(cols, col)
+			vals = append(vals, val)
+		}
+	}
+
+	if _, ok := any(m).(repository.Updatable); ok {
+		cols = append(cols, "updated_at")
+		vals = append(vals, time.Now())
+	}
+	return cols, vals
+}
+
+func (bc *BaseController[T]) runBulk(
+	ctx context.Context,
+	w http.ResponseWriter,
+	ids []any,
+	done, msg string,
+	fn func(ctx context.Context, ids []interface{}) ([]repository.BulkResult, error),
+) {
+	results := make([]bulkResult, len(ids))
+	var keys []interface{}
+	var positions []int
+	for i, id := range ids {
+		results[i].ID = id
+		key, err := bc.parseKey(helper.KeyString(id))
+		if err != nil {
+			results[i].Status = "invalid"
+			continue
+		}
+		keys = append(keys, keyParam(key))
+		positions = append(positions, i)
+	}
+
+	var total int64
+	if len(keys) > 0 {
+		outcomes, err := fn(ctx, keys)
+		if err != nil {
+			writeRepoError(ctx, w, http.StatusInternalServerError, msg, err)
+			return
+		}
+		for j, o := range outcomes {
+			total += o.Affected
+			results[positions[j]].Status = bulkStatus(o.Err, done)
+		}
+	}
+
+	helper.JSONResponse(w, http.StatusOK, map[string]any{
+		"affected": total,
+		"results":  results,
+	})
+}
+
+// bulkStatus names the outcome of one key with the code the single-record
+// action would answer with.
+func bulkStatus(err error, done string) string {
+	switch {
+	case err == nil:
+		return done
+	case errors.Is(err, repository.ErrAlreadyDeleted):
+		return helper.ErrorCodeAlreadyDeleted
+	case errors.Is(err, repository.ErrNotDeleted):
+		return helper.ErrorCodeNotDeleted
+	}
+	return helper.ErrorCodeNotFound
+}
+
+func (bc *BaseController[T]) setKey(m T, key []interface{}) {
+	if bc.SetKey != nil {
+		bc.SetKey(m, key)
+		return
+	}
+	bc.SetPK(m, fmt.Sprint(key[0]))
+}
+
+func (bc *BaseController[T]) generateKey(m T) error {
+	key := repository.PrimaryKeyValues(m)
+	if len(key) != 1 {
+		return nil
+	}
+
+	strategy := bc.IDStrategy
+	if strategy == nil {
+		if id, ok := key[0].(string); !ok || id != "" {
+			return nil
+		}
+		strategy = helper.ULIDStrategy{Gen: bc.ULIDGen}
+	}
+
+	if !helper.IsEmptyValue(key[0]) {
+		if err := strategy.ValidateID(fmt.Sprint(key[0])); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	id, err := strategy.NewID()
+	if err != nil || id == "" {
+		return err
+	}
+	bc.setKey(m, []interface{}{id})
+	return nil
+}
+
+func (bc *BaseController[T]) listFields(r *http.Request, orderBy string) []string {
+	fields := helper.ParseFieldsParam(r.URL.Query().Get("fields"), bc.Repo.New().Columns())
+	return helper.EnsureKeyFields(fields, bc.keys(), orderBy)
+}
+
+// strictQuery answers 400 with every problem found in the query params when
+// the domain is strict. Otherwise the readers below fall back or skip, except
+// for sorting or filtering outside the domain's rules, which is always refused.
+func (bc *BaseController[T]) strictQuery(w http.ResponseWriter, r *http.Request) bool {
+	schema := bc.Repo.New().Schema()
+	check := helper.CheckQueryLimits
+	if bc.StrictQuery {
+		check = helper.CheckQueryParams
+	}
+	rules := bc.queryRules()
+	if r.URL.Query().Get("q") != "" {
+		rules.Sortable = append(rules.Sortable, helper.RelevanceColumn)
+	}
+	if problems := check(r, rules, schema); len(problems) > 0 {
+		helper.JSONQueryProblems(w, problems)
+		return false
+	}
+	return true
+}
+
+func (bc *BaseController[T]) queryRules() helper.QueryRules {
+	m := bc.Repo.New()
+	return helper.QueryRules{
+		Fields:   m.Columns(),
+		Sortable: repository.SortableColumns(m),
+		Filters: helper.FilterRules{
+			Columns:     repository.FilterableColumns(m),
+			MaxFilters:  bc.MaxFilters,
+			MaxInValues: bc.MaxInValues,
+		},
+	}
+}
+
+func (bc *BaseController[T]) filters(r *http.Request) []helper.Filter {
+	filters, _ := helper.FilterParams(r, bc.queryRules().Filters, bc.Repo.New().Schema())
+	return filters
+}
+
+// searchFilters adds the q param to the filters as a search over the
+// model's searchable columns.
+func (bc *BaseController[T]) searchFilters(w http.ResponseWriter, r *http.Request) ([]helper.Filter, bool) {
+	filters := bc.filters(r)
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		return filters, true
+	}
+	cols := repository.SearchableColumns(bc.Repo.New())
+	if len(cols) == 0 {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid search", repository.ErrNotSearchable)
+		return nil, false
+	}
+	return append(filters, helper.SearchFilter(cols, q)), true
+}
+
+func (bc *BaseController[T]) maxBulkIDs() int {
+	if bc.MaxBulkIDs <= 0 {
+		return defaultMaxBulkIDs
+	}
+	return bc.MaxBulkIDs
+}
+
+func (bc *BaseController[T]) pageOffset(w http.ResponseWriter, r *http.Request, limit int, pageCursor *helper.PageCursor) (int, bool) {
+	offset, err := helper.GetOffsetParams(r, limit, bc.MaxOffset, pageCursor)
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid Page", err)
+		return 0, false
+	}
+	return offset, true
+}
+
+// The count runs as a separate query, so it can drift from the page when
+// rows are written in between; it is only paid for when asked.
+func (bc *BaseController[T]) setTotal(ctx context.Context, w http.ResponseWriter, r *http.Request, count func(ctx context.Context) (int64, error)) bool {
+	if !helper.WantsTotal(r) {
+		return true
+	}
+	n, err := count(ctx)
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Count error", err)
+		return false
+	}
+	w.Header().Set("X-Total-Count", strconv.FormatInt(n, 10))
+	return true
+}
+
+// Cursors are left out in page-number mode, which walks by offset instead.
+func (bc *BaseController[T]) setPageCursors(w http.ResponseWriter, r *http.Request, cursors func() (next, prev string)) {
+	query := r.URL.Query()
+	if query.Has("page") || query.Has("offset") {
+		return
+	}
+	next, prev := cursors()
+	if next != "" {
+		w.Header().Set("X-Page-Cursor", next)
+	}
+	if prev != "" {
+		w.Header().Set("X-Prev-Page-Cursor", prev)
+	}
+}
+
+type includes struct {
+	names     []string
+	relations []repository.Relation
+	fields    map[string][]string
+}
+
+func (bc *BaseController[T]) includes(w http.ResponseWriter, r *http.Request) (includes, bool) {
+	names, fields := helper.GetIncludeParams(r)
+	relations, err := repository.FindRelations(bc.Repo.New(), names)
+	if err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid include", err)
+		return includes{}, false
+	}
+	return includes{names: names, relations: relations, fields: fields}, true
+}
+
+func (bc *BaseController[T]) loadIncludes(ctx context.Context, records []map[string]any, inc includes) error {
+	if len(inc.names) == 0 || len(records) == 0 {
+		return nil
+	}
+	return bc.Repo.Include(ctx, records, inc.names, inc.fields)
+}
+
+// A narrowed selection still has to read the columns the relations join on.
+func (inc includes) queryFields(fields []string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+	for _, rel := range inc.relations {
+		if !slices.Contains(fields, rel.LocalKey) {
+			fields = append(slices.Clone(fields), rel.LocalKey)
+		}
+	}
+	return fields
+}
+
+func (inc includes) responseFields(fields []string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+	return append(slices.Clone(fields), inc.names...)
+}
+
+func (bc *BaseController[T]) versionFields(fields []string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+	for _, col := range repository.ETagColumns(bc.Repo.New()) {
+		if !slices.Contains(fields, col) {
+			fields = append(slices.Clone(fields), col)
+		}
+	}
+	return fields
+}
+
+func (bc *BaseController[T]) setETag(w http.ResponseWriter, record map[string]any) {
+	m := bc.Repo.New()
+	if repository.VersionColumn(m) != "" {
+		w.Header().Set("ETag", repository.RecordETag(m, record))
+	}
+}
+
+// Written records are read back from the primary, so the response shows the
+// defaults, timestamps and sanitized values as they were stored.
+func (bc *BaseController[T]) writeRepresentation(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, m T) {
+	fields := helper.GetFieldsParamOne(r, bc.Repo.New().Columns())
+
+	record, err := bc.Repo.Detail(repository.WithPrimary(ctx), keyParam(repository.PrimaryKeyValues(m)), bc.versionFields(fields))
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Detail error", err)
+		return
+	}
+
+	w.Header().Set("Preference-Applied", helper.PreferRepresentation)
+	bc.setETag(w, record)
+	helper.JSONResponse(w, status, helper.FilterJSON(record, fields))
+}
+
+func (bc *BaseController[T]) writeRepresentations(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, items []T) {
+	keys := bc.keys()
+	fields := helper.EnsureKeyFields(helper.GetFieldsParamOne(r, bc.Repo.New().Columns()), keys, keys[0])
+
+	ids := make([]string, len(items))
+	for i, m := range items {
+		ids[i] = helper.JoinKey(repository.PrimaryKeyValues(m))
+	}
+
+	list, err := bc.Repo.Bulk(repository.WithPrimary(ctx), ids, len(ids), 0, nil, keys[0], "ASC", fields)
+	if err != nil {
+		writeRepoError(ctx, w, http.StatusInternalServerError, "Bulk error", err)
+		return
+	}
+
+	byKey := make(map[string]map[string]any, len(list))
+	for _, record := range list {
+		vals := make([]interface{}, len(keys))
+		for i, col := range keys {
+			vals[i] = record[col]
+		}
+		byKey[helper.JoinKey(vals)] = record
+	}
+
+	records := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		if record, ok := byKey[id]; ok {
+			records = append(records, record)
+		}
+	}
+
+	w.Header().Set("Preference-Applied", helper.PreferRepresentation)
+	helper.JSONResponse(w, status, helper.FilterList(records, fields))
+}
+
+func (bc *BaseController[T]) ifMatch(w http.ResponseWriter, r *http.Request) (string, bool) {
+	etag := r.Header.Get("If-Match")
+	if etag == "" && bc.RequireIfMatch && repository.VersionColumn(bc.Repo.New()) != "" {
+		helper.JSONErrorSimple(w, http.StatusPreconditionRequired, "If-Match header required")
+		return "", false
+	}
+	return etag, true
+}
+
+type bulkMutation struct {
+	IDs    []any                  `json:"ids"`
+	Filter []string               `json:"filter"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+type bulkResult struct {
+	ID     any    `json:"id"`
+	Status string `json:"status"`
+}
+
+func decodeBulkMutation(w http.ResponseWriter, r *http.Request) (bulkMutation, bool) {
+	var input bulkMutation
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&input); err != nil {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid JSON", err)
+		return input, false
+	}
+	return input, true
+}
+
+func validBulkIDs(w http.ResponseWriter, ids []any) bool {
+	if len(ids) == 0 || len(ids) > maxBulkMutation {
+		helper.JSONErrorSimple(w, http.StatusBadRequest,
+			fmt.Sprintf("Ids list must contain between 1 and %d items", maxBulkMutation))
+		return false
+	}
+	return true
+}
+
+func setTimestamps(m any, now time.Time) {
+	if c, ok := m.(repository.Creatable); ok {
+		c.SetCreatedAt(now)
+	}
+	if u, ok := m.(repository.Updatable); ok {
+		u.SetUpdatedAt(now)
+	}
+}
+
+func keyParam(key []interface{}) interface{} {
+	if len(key) == 1 {
+		return key[0]
+	}
+	return key
+}
+
+func keyResponse(m repository.BaseModel) any {
+	cols := repository.PrimaryKeys(m)
+	vals := repository.PrimaryKeyValues(m)
+	if len(cols) == 1 {
+		return vals[0]
+	}
+	key := make(map[string]any, len(cols))
+	for i, col := range cols {
+		key[col] = vals[i]
+	}
+	return key
+}
+
+func writeKeyError(w http.ResponseWriter, message string, err error) {
+	switch {
+	case errors.Is(err, helper.ErrIDRequired):
+		helper.JSONError(w, http.StatusBadRequest, "Missing Id", err)
+	case errors.Is(err, helper.ErrInvalidID):
+		helper.JSONError(w, http.StatusBadRequest, "Invalid Id", err)
+	default:
+		helper.JSONError(w, http.StatusInternalServerError, message, err)
+	}
+}
+
+func writeRepoError(ctx context.Context, w http.ResponseWriter, status int, message string, err error) {
+	if helper.IsTimeoutError(ctx, err) {
+		helper.JSONError(w, http.StatusGatewayTimeout, "Query timeout", err)
+		return
+	}
+
+	if errors.Is(err, helper.ErrInvalidCursor) {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid Page Cursor", err)
+		return
+	}
+
+	if errors.Is(err, repository.ErrMissingFilter) {
+		helper.JSONError(w, http.StatusBadRequest, "Invalid filter", err)
+		return
+	}
+
+	if errors.Is(err, repository.ErrIncludeTooLarge) {
+		helper.JSONError(w, http.StatusBadRequest, "Include too large", err)
+		return
+	}
+
+	if errors.Is(err, repository.ErrPreconditionFailed) {
+		helper.JSONError(w, http.StatusPreconditionFailed, "Precondition failed", err)
+		return
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, repository.ErrNotSoftDeletable):
+		helper.JSONErrorCode(w, http.StatusNotFound, helper.ErrorCodeNotFound, "Not found", err)
+		return
+	case errors.Is(err, repository.ErrAlreadyDeleted):
+		helper.JSONErrorCode(w, http.StatusConflict, helper.ErrorCodeAlreadyDeleted, "Already deleted", err)
+		return
+	case errors.Is(err, repository.ErrNotDeleted):
+		helper.JSONErrorCode(w, http.StatusConflict, helper.ErrorCodeNotDeleted, "Not deleted", err)
+		return
+	}
+
+	switch helper.ClassifyDBError(err) {
+	case helper.DBErrorDuplicate:
+		helper.JSONError(w, http.StatusConflict, "Duplicate record", err)
+	case helper.DBErrorForeignKey:
+		helper.JSONError(w, http.StatusConflict, "Related record constraint failed", err)
+	default:
+		helper.JSONError(w, status, message, err)
+	}
+}
@@ -3,19 +3,31 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/not-empty/grit-microframework-go/app/config"
+	"github.com/not-empty/grit-microframework-go/app/helper"
 )
 
 type DatabaseConfig struct {
-	User    string
-	Pass    string
-	Host    string
-	Port    string
-	Name    string
-	MaxOpen int
-	MaxIdle int
+	Driver   string
+	SSLMode  string
+	User     string
+	Pass     string
+	Host     string
+	Port     string
+	Name     string
+	Schema   string
+	MaxOpen  int
+	MaxIdle  int
+	Replicas []string
 }
 
 var SqlOpenFunc = sql.Open
@@ -43,27 +55,41 @@ func LoadDatabaseConfig() DatabaseConfig {
 	}
 
 	return DatabaseConfig{
-		User:    user,
-		Pass:    pass,
-		Host:    host,
-		Port:    port,
-		Name:    name,
-		MaxOpen: config.AppConfig.DBMaxConn,
-		MaxIdle: config.AppConfig.DBMaxIdle,
+		Driver:   config.AppConfig.DBDriver,
+		SSLMode:  config.AppConfig.DBSSLMode,
+		User:     user,
+		Pass:     pass,
+		Host:     host,
+		Port:     port,
+		Name:     name,
+		Schema:   config.AppConfig.DBSchema,
+		MaxOpen:  config.AppConfig.DBMaxConn,
+		MaxIdle:  config.AppConfig.DBMaxIdle,
+		Replicas: ParseReplicas(config.AppConfig.DBReplicas),
 	}
 }
 
 func Init(cfg DatabaseConfig) *sql.DB {
-	if cfg.User == "" || cfg.Pass == "" || cfg.Host == "" || cfg.Port == "" || cfg.Name == "" || cfg.MaxOpen <= 0 || cfg.MaxIdle < 0 {
+	if !IsValidConfig(cfg) {
 		panic("Missing or invalid database configuration")
 	}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", cfg.User, cfg.Pass, cfg.Host, cfg.Port, cfg.Name)
-	db, err := SqlOpenFunc("mysql", dsn)
+	driver, dsn := BuildDSN(cfg)
+	db, err := SqlOpenFunc(driver, dsn)
 	if err != nil {
 		panic(fmt.Sprintf("Error opening database: %v", err))
 	}
 
+	dialect, ok := helper.GetDialect(cfg.Driver)
+	if !ok {
+		dialect, _ = helper.GetDialect(driver)
+	}
+	helper.SetDialect(dialect)
+
+	if driver == helper.DriverSQLite && IsSQLiteMemory(cfg.Name) {
+		cfg.MaxOpen, cfg.MaxIdle = 1, 1
+	}
+
 	db.SetMaxOpenConns(cfg.MaxOpen)
 	db.SetMaxIdleConns(cfg.MaxIdle)
 
@@ -71,5 +97,130 @@ func Init(cfg DatabaseConfig) *sql.DB {
 		panic(fmt.Sprintf("Error connecting to database: %v", err))
 	}
 
+	if cfg.Schema != "" {
+		if err := LoadSchema(db, cfg.Schema); err != nil {
+			panic(fmt.Sprintf("Error loading database schema: %v", err))
+		}
+	}
+
 	return db
 }
+
+func InitReplicas(cfg DatabaseConfig) []*sql.DB {
+	replicas := make([]*sql.DB, 0, len(cfg.Replicas))
+	for _, replica := range cfg.Replicas {
+		driver, dsn := ReplicaDSN(cfg, replica)
+		db, err := SqlOpenFunc(driver, dsn)
+		if err != nil {
+			panic(fmt.Sprintf("Error opening replica %s: %v", replica, err))
+		}
+
+		db.SetMaxOpenConns(cfg.MaxOpen)
+		db.SetMaxIdleConns(cfg.MaxIdle)
+
+		if err := DbPingFunc(db); err != nil {
+			panic(fmt.Sprintf("Error connecting to replica %s: %v", replica, err))
+		}
+		replicas = append(replicas, db)
+	}
+	return replicas
+}
+
+func ParseReplicas(value string) []string {
+	var replicas []string
+	for _, replica := range strings.Split(value, ",") {
+		if replica = strings.TrimSpace(replica); replica != "" {
+			replicas = append(replicas, replica)
+		}
+	}
+	return replicas
+}
+
+func ReplicaDSN(cfg DatabaseConfig, replica string) (string, string) {
+	driver, _ := BuildDSN(cfg)
+	if strings.ContainsAny(replica, "@/") || isSQLiteDriver(cfg.Driver) {
+		return driver, replica
+	}
+
+	host, port, err := net.SplitHostPort(replica)
+	if err != nil {
+		host, port = replica, cfg.Port
+	}
+	cfg.Host, cfg.Port = host, port
+	return BuildDSN(cfg)
+}
+
+func IsValidConfig(cfg DatabaseConfig) bool {
+	if cfg.Name == "" || cfg.MaxOpen <= 0 || cfg.MaxIdle < 0 {
+		return false
+	}
+	if isSQLiteDriver(cfg.Driver) {
+		return true
+	}
+	return cfg.User != "" && cfg.Pass != "" && cfg.Host != "" && cfg.Port != ""
+}
+
+func IsSQLiteMemory(name string) bool {
+	return strings.HasPrefix(name, ":memory:") || strings.Contains(name, "mode=memory")
+}
+
+func LoadSchema(db *sql.DB, path string) error {
+	if !filepath.IsAbs(path) {
+		root, err := helper.GetProjectRoot()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(root, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range strings.Split(string(content), ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isSQLiteDriver(driver string) bool {
+	switch strings.ToLower(driver) {
+	case "sqlite", helper.DriverSQLite:
+		return true
+	}
+	return false
+}
+
+func BuildDSN(cfg DatabaseConfig) (string, string) {
+	switch strings.ToLower(cfg.Driver) {
+	case "", helper.DriverMySQL, "mariadb", "tidb":
+		return helper.DriverMySQL, fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", cfg.User, cfg.Pass, cfg.Host, cfg.Port, cfg.Name)
+	case helper.DriverPostgres, "postgresql", "pgsql":
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := (&url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.User, cfg.Pass),
+			Host:     net.JoinHostPort(cfg.Host, cfg.Port),
+			Path:     "/" + cfg.Name,
+			RawQuery: "sslmode=" + url.QueryEscape(sslMode),
+		}).String()
+		return helper.DriverPostgres, dsn
+	case "sqlite", helper.DriverSQLite:
+		sep := "?"
+		if strings.Contains(cfg.Name, "?") {
+			sep = "&"
+		}
+		return helper.DriverSQLite, cfg.Name + sep + "_busy_timeout=5000&_foreign_keys=on"
+	default:
+		panic(fmt.Sprintf("Unsupported database driver: %s", cfg.Driver))
+	}
+}
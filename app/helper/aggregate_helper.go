@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Aggregate struct {
+	Func  string
+	Field string
+}
+
+var ErrInvalidAggregate = errors.New("invalid aggregate")
+
+var aggregateFuncs = []string{"count", "count_distinct", "sum", "avg", "min", "max"}
+
+var numericSchemaTypes = []string{"int", "*int", "int64", "*int64", "uint64", "*uint64", "uint", "*uint", "float64", "*float64", "float32", "*float32", "float", "decimal", "*decimal"}
+
+// Alias is the key the aggregate is returned under, e.g. sum_total.
+func (a Aggregate) Alias() string {
+	if a.Field == "" {
+		return a.Func
+	}
+	return a.Func + "_" + a.Field
+}
+
+func (a Aggregate) Expression(d Dialect) string {
+	if a.Field == "" {
+		return "COUNT(*)"
+	}
+	col := d.QuoteIdentifier(a.Field)
+	if a.Func == "count_distinct" {
+		return "COUNT(DISTINCT " + col + ")"
+	}
+	return strings.ToUpper(a.Func) + "(" + col + ")"
+}
+
+// SchemaType is the schema type the aggregate's result is scanned as.
+func (a Aggregate) SchemaType(schema map[string]string) string {
+	typ := strings.ToLower(schema[a.Field])
+	switch a.Func {
+	case "count", "count_distinct":
+		return "int"
+	case "avg":
+		if strings.TrimPrefix(typ, "*") == "decimal" {
+			return "decimal"
+		}
+		return "float64"
+	}
+	return typ
+}
+
+func IsNumericType(typ string) bool {
+	return slices.Contains(numericSchemaTypes, strings.ToLower(typ))
+}
+
+// ParseAggregates reads entries such as "count", "count_distinct:customer_id"
+// or "sum:total", each of which may also be a comma-separated list. Only
+// count may omit the column, and sum and avg need a numeric one.
+func ParseAggregates(raw []string, allowed []string, schema map[string]string) ([]Aggregate, error) {
+	var aggs []Aggregate
+	for _, entry := range raw {
+		for _, item := range strings.Split(entry, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+
+			fn, field, _ := strings.Cut(item, ":")
+			a := Aggregate{Func: strings.ToLower(strings.TrimSpace(fn)), Field: strings.TrimSpace(field)}
+			if !validAggregate(a, allowed, schema) {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidAggregate, item)
+			}
+			if !slices.Contains(aggs, a) {
+				aggs = append(aggs, a)
+			}
+		}
+	}
+
+	if len(aggs) == 0 {
+		aggs = append(aggs, Aggregate{Func: "count"})
+	}
+	return aggs, nil
+}
+
+func validAggregate(a Aggregate, allowed []string, schema map[string]string) bool {
+	if !slices.Contains(aggregateFuncs, a.Func) {
+		return false
+	}
+	if a.Field == "" {
+		return a.Func == "count"
+	}
+	if !slices.Contains(allowed, a.Field) {
+		return false
+	}
+	if a.Func == "sum" || a.Func == "avg" {
+		return IsNumericType(schema[a.Field])
+	}
+	return true
+}
+
+// ParseGroupBy rejects unknown columns rather than dropping them, since a
+// silently ignored group_by changes the meaning of every returned number.
+func ParseGroupBy(raw string, allowed []string) ([]string, error) {
+	var cols []string
+	for _, col := range strings.Split(raw, ",") {
+		col = strings.TrimSpace(col)
+		if col == "" || slices.Contains(cols, col) {
+			continue
+		}
+		if !slices.Contains(allowed, col) {
+			return nil, fmt.Errorf("%w: group_by %q", ErrInvalidAggregate, col)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/not-empty/grit-microframework-go/app/config"
+)
+
+func QueryContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 && config.AppConfig != nil {
+		timeout = time.Duration(config.AppConfig.DBQueryTimeout) * time.Millisecond
+	}
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+func IsTimeoutError(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
@@ -39,6 +39,12 @@ func (jt JSONTime) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + t.Format("2006-01-02 15:04:05") + `"`), nil
 }
 
+// String keeps the fractional seconds, so values that differ below a second
+// (ETags, keys) do not collide.
+func (jt JSONTime) String() string {
+	return time.Time(jt).Format("2006-01-02 15:04:05.999999999")
+}
+
 func (jt JSONTime) Value() (driver.Value, error) {
 	return time.Time(jt), nil
 }
@@ -0,0 +1,337 @@
+package helper
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite3"
+)
+
+type DBErrorKind int
+
+const (
+	DBErrorUnknown DBErrorKind = iota
+	DBErrorDuplicate
+	DBErrorForeignKey
+	DBErrorNotNull
+	DBErrorDeadlock
+	DBErrorLockTimeout
+)
+
+type Dialect interface {
+	Name() string
+	QuoteIdentifier(name string) string
+	Placeholder(n int) string
+	Limit(limit, offset string) string
+	CurrentTimestamp() string
+	Upsert(keyCols, updateCols []string) string
+	SupportsDefaultKeyword() bool
+	ForUpdate() string
+	ClassifyError(err error) DBErrorKind
+}
+
+var (
+	dialectMu     sync.RWMutex
+	activeDialect Dialect = MySQLDialect{}
+	dialects              = map[string]Dialect{
+		"mysql":      MySQLDialect{},
+		"mariadb":    MySQLDialect{},
+		"tidb":       MySQLDialect{},
+		"postgres":   PostgresDialect{},
+		"postgresql": PostgresDialect{},
+		"pgsql":      PostgresDialect{},
+		"sqlite":     SQLiteDialect{},
+		"sqlite3":    SQLiteDialect{},
+	}
+)
+
+func RegisterDialect(name string, d Dialect) {
+	dialectMu.Lock()
+	defer dialectMu.Unlock()
+	dialects[strings.ToLower(name)] = d
+}
+
+func GetDialect(name string) (Dialect, bool) {
+	dialectMu.RLock()
+	defer dialectMu.RUnlock()
+	if name == "" {
+		name = DriverMySQL
+	}
+	d, ok := dialects[strings.ToLower(name)]
+	return d, ok
+}
+
+func SetDialect(d Dialect) {
+	dialectMu.Lock()
+	defer dialectMu.Unlock()
+	if d == nil {
+		d = MySQLDialect{}
+	}
+	activeDialect = d
+}
+
+func CurrentDialect() Dialect {
+	dialectMu.RLock()
+	defer dialectMu.RUnlock()
+	return activeDialect
+}
+
+func ClassifyDBError(err error) DBErrorKind {
+	if err == nil {
+		return DBErrorUnknown
+	}
+	return CurrentDialect().ClassifyError(err)
+}
+
+func Rebind(d Dialect, query string) string {
+	if d.Placeholder(1) == "?" {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func quoteIdentifierWith(name, quote string) string {
+	parts := strings.Split(strings.Trim(name, "`\""), ".")
+	for i, p := range parts {
+		p = strings.Trim(p, "`\"")
+		parts[i] = quote + strings.ReplaceAll(p, quote, quote+quote) + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+func limitClause(limit, offset string) string {
+	if offset == "" {
+		return "LIMIT " + limit
+	}
+	return "LIMIT " + limit + " OFFSET " + offset
+}
+
+type MySQLDialect struct{}
+
+func (MySQLDialect) Name() string {
+	return DriverMySQL
+}
+
+func (MySQLDialect) QuoteIdentifier(name string) string {
+	return quoteIdentifierWith(name, "`")
+}
+
+func (MySQLDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (MySQLDialect) Limit(limit, offset string) string {
+	return limitClause(limit, offset)
+}
+
+func (MySQLDialect) CurrentTimestamp() string {
+	return "NOW()"
+}
+
+// Upsert keeps VALUES(col) although MySQL 8.0.20 deprecates it: the row alias
+// form (AS new ... new.col) needs 8.0.19+ and is rejected by MariaDB and TiDB,
+// which share this dialect.
+func (d MySQLDialect) Upsert(keyCols, updateCols []string) string {
+	if len(updateCols) == 0 {
+		updateCols = keyCols[:1]
+	}
+	sets := make([]string, len(updateCols))
+	for i, col := range updateCols {
+		esc := d.QuoteIdentifier(col)
+		sets[i] = esc + " = VALUES(" + esc + ")"
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+func (MySQLDialect) SupportsDefaultKeyword() bool {
+	return true
+}
+
+func (MySQLDialect) ForUpdate() string {
+	return "FOR UPDATE"
+}
+
+func (MySQLDialect) ClassifyError(err error) DBErrorKind {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return DBErrorUnknown
+	}
+	switch myErr.Number {
+	case 1062, 1586:
+		return DBErrorDuplicate
+	case 1216, 1217, 1451, 1452:
+		return DBErrorForeignKey
+	case 1048, 1364:
+		return DBErrorNotNull
+	case 1213:
+		return DBErrorDeadlock
+	case 1205:
+		return DBErrorLockTimeout
+	}
+	return DBErrorUnknown
+}
+
+type PostgresDialect struct{}
+
+func (PostgresDialect) Name() string {
+	return DriverPostgres
+}
+
+func (PostgresDialect) QuoteIdentifier(name string) string {
+	return quoteIdentifierWith(name, `"`)
+}
+
+func (PostgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (PostgresDialect) Limit(limit, offset string) string {
+	return limitClause(limit, offset)
+}
+
+func (PostgresDialect) CurrentTimestamp() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (d PostgresDialect) Upsert(keyCols, updateCols []string) string {
+	target := "ON CONFLICT (" + strings.Join(QuoteIdentifiers(d, keyCols), ", ") + ")"
+	if len(updateCols) == 0 {
+		return target + " DO NOTHING"
+	}
+	sets := make([]string, len(updateCols))
+	for i, col := range updateCols {
+		esc := d.QuoteIdentifier(col)
+		sets[i] = esc + " = EXCLUDED." + esc
+	}
+	return target + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+func (PostgresDialect) SupportsDefaultKeyword() bool {
+	return true
+}
+
+func (PostgresDialect) ForUpdate() string {
+	return "FOR UPDATE"
+}
+
+func (PostgresDialect) ClassifyError(err error) DBErrorKind {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return DBErrorUnknown
+	}
+	switch pqErr.Code {
+	case "23505":
+		return DBErrorDuplicate
+	case "23503":
+		return DBErrorForeignKey
+	case "23502":
+		return DBErrorNotNull
+	case "40P01":
+		return DBErrorDeadlock
+	case "55P03":
+		return DBErrorLockTimeout
+	}
+	return DBErrorUnknown
+}
+
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) Name() string {
+	return DriverSQLite
+}
+
+func (SQLiteDialect) QuoteIdentifier(name string) string {
+	return quoteIdentifierWith(name, `"`)
+}
+
+func (SQLiteDialect) Placeholder(int) string {
+	return "?"
+}
+
+func (SQLiteDialect) Limit(limit, offset string) string {
+	return limitClause(limit, offset)
+}
+
+func (SQLiteDialect) CurrentTimestamp() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (d SQLiteDialect) Upsert(keyCols, updateCols []string) string {
+	target := "ON CONFLICT (" + strings.Join(QuoteIdentifiers(d, keyCols), ", ") + ")"
+	if len(updateCols) == 0 {
+		return target + " DO NOTHING"
+	}
+	sets := make([]string, len(updateCols))
+	for i, col := range updateCols {
+		esc := d.QuoteIdentifier(col)
+		sets[i] = esc + " = excluded." + esc
+	}
+	return target + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+func (SQLiteDialect) SupportsDefaultKeyword() bool {
+	return false
+}
+
+// SQLite serializes writers on the whole database, so there is no row lock.
+func (SQLiteDialect) ForUpdate() string {
+	return ""
+}
+
+// go-sqlite3 only exposes its error type when built with cgo, so the
+// classification relies on the stable messages SQLite produces.
+func (SQLiteDialect) ClassifyError(err error) DBErrorKind {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"),
+		strings.Contains(msg, "PRIMARY KEY constraint failed"):
+		return DBErrorDuplicate
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return DBErrorForeignKey
+	case strings.Contains(msg, "NOT NULL constraint failed"):
+		return DBErrorNotNull
+	case strings.Contains(msg, "database is locked"),
+		strings.Contains(msg, "database table is locked"):
+		return DBErrorLockTimeout
+	}
+	return DBErrorUnknown
+}
+
+func QuoteIdentifiers(d Dialect, names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = d.QuoteIdentifier(name)
+	}
+	return quoted
+}
@@ -7,8 +7,15 @@ import (
 	"github.com/not-empty/grit-microframework-go/app/config"
 )
 
+const (
+	ErrorCodeNotFound       = "not_found"
+	ErrorCodeAlreadyDeleted = "already_deleted"
+	ErrorCodeNotDeleted     = "not_deleted"
+)
+
 type ErrorResponse struct {
 	Error  string `json:"error"`
+	Code   string `json:"code,omitempty"`
 	Detail string `json:"detail"`
 }
 
@@ -17,6 +24,10 @@ func JSONErrorSimple(w http.ResponseWriter, status int, message string) {
 }
 
 func JSONError(w http.ResponseWriter, status int, message string, err error) {
+	JSONErrorCode(w, status, "", message, err)
+}
+
+func JSONErrorCode(w http.ResponseWriter, status int, code, message string, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
@@ -24,6 +35,7 @@ func JSONError(w http.ResponseWriter, status int, message string, err error) {
 
 	response := ErrorResponse{
 		Error:  message,
+		Code:   code,
 		Detail: "",
 	}
 
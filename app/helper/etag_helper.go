@@ -0,0 +1,25 @@
+package helper
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+func ETag(version any) string {
+	sum := sha1.Sum([]byte(fmt.Sprint(version)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// MatchETag checks an If-Match header, which takes the strong comparison
+// (RFC 9110 13.1.1): a weak W/ validator never matches.
+func MatchETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 )
 
@@ -39,28 +40,24 @@ func GetFieldsParamList(r *http.Request, allowedFields []string, orderBy string)
 }
 
 func EnsurePaginationFields(fields []string, orderBy string) []string {
+	return EnsureKeyFields(fields, []string{"id"}, orderBy)
+}
+
+func EnsureKeyFields(fields []string, keys []string, orderBy string) []string {
 	if len(fields) == 0 {
 		return fields
 	}
 
-	hasID := false
-	hasOrder := false
-
-	for _, f := range fields {
-		if f == "id" {
-			hasID = true
-		}
-		if orderBy != "" && f == orderBy {
-			hasOrder = true
+	for _, key := range keys {
+		if !slices.Contains(fields, key) {
+			fields = append(fields, key)
 		}
 	}
 
-	if !hasID {
-		fields = append(fields, "id")
-	}
-
-	if orderBy != "" && orderBy != "id" && !hasOrder {
-		fields = append(fields, orderBy)
+	for _, term := range SortTerms(orderBy) {
+		if !slices.Contains(fields, term.Column) {
+			fields = append(fields, term.Column)
+		}
 	}
 
 	return fields
@@ -107,14 +104,42 @@ func ValidateOrderBy(orderBy string, allowed []string) string {
 	return "id"
 }
 
+func EscapeField(field string) string {
+	return CurrentDialect().QuoteIdentifier(field)
+}
+
+func EscapeFields(fields []string) []string {
+	return QuoteIdentifiers(CurrentDialect(), fields)
+}
+
+// Deprecated: use EscapeField, which follows the configured dialect.
 func EscapeMysqlField(field string) string {
-	return "`" + field + "`"
+	return MySQLDialect{}.QuoteIdentifier(field)
 }
 
+// Deprecated: use EscapeFields, which follows the configured dialect.
 func EscapeMysqlFields(fields []string) []string {
-	escapedFields := make([]string, len(fields))
-	for i, field := range fields {
-		escapedFields[i] = EscapeMysqlField(field)
+	return QuoteIdentifiers(MySQLDialect{}, fields)
+}
+
+// GetIncludeParams reads ?include=a,b and the optional fields[a]=x,y lists
+// that narrow each included relation.
+func GetIncludeParams(r *http.Request) (names []string, fields map[string][]string) {
+	query := r.URL.Query()
+	for _, name := range strings.Split(query.Get("include"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(names, name) {
+			continue
+		}
+		names = append(names, name)
+		if raw := query.Get("fields[" + name + "]"); raw != "" {
+			if fields == nil {
+				fields = make(map[string][]string)
+			}
+			for _, f := range strings.Split(raw, ",") {
+				fields[name] = append(fields[name], strings.TrimSpace(f))
+			}
+		}
 	}
-	return escapedFields
+	return names, fields
 }
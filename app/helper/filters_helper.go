@@ -1,105 +1,484 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Filter is either a condition on one column or, when Operator is "or" or
+// "and", a group joining the conditions in Group. Type is the column's
+// schema type once the filter has been checked against a schema, and decides
+// how Value is bound.
 type Filter struct {
 	Field    string
 	Operator string
 	Value    string
+	Group    []Filter
+	Type     string
 }
 
-func GetFilters(r *http.Request, allowed []string) (filters []Filter) {
-	query := r.URL.Query()
+var ErrInvalidFilter = errors.New("invalid filter")
 
-	allowedMap := make(map[string]struct{})
-	for _, a := range allowed {
-		allowedMap[a] = struct{}{}
+// ErrFilterNotAllowed marks a filter that is well formed but outside what
+// the domain lets clients filter on. It is rejected even in lenient mode.
+var ErrFilterNotAllowed = errors.New("filter not allowed")
+
+const (
+	DefaultMaxFilters  = 10
+	DefaultMaxInValues = 100
+)
+
+// IndexedOperators are the operators an index can serve, the ones cmd/domain
+// allows on indexed columns.
+var IndexedOperators = []string{"eql", "gt", "lt", "gte", "lte", "btw", "in", "stw", "nul", "nnu"}
+
+// FilterRules maps each filterable column to the operators allowed on it,
+// where an empty list allows all of them. A zero cap uses its default.
+type FilterRules struct {
+	Columns     map[string][]string
+	MaxFilters  int
+	MaxInValues int
+}
+
+// AllowColumns allows every operator on the given columns.
+func AllowColumns(columns []string) FilterRules {
+	rules := FilterRules{Columns: make(map[string][]string, len(columns))}
+	for _, col := range columns {
+		rules.Columns[col] = nil
 	}
+	return rules
+}
 
-	for _, raw := range query["filter"] {
-		parts := strings.SplitN(raw, ":", 3)
-		if len(parts) != 3 {
-			continue
+func (fr FilterRules) maxFilters() int {
+	if fr.MaxFilters <= 0 {
+		return DefaultMaxFilters
+	}
+	return fr.MaxFilters
+}
+
+func (fr FilterRules) maxInValues() int {
+	if fr.MaxInValues <= 0 {
+		return DefaultMaxInValues
+	}
+	return fr.MaxInValues
+}
+
+// tooMany reports a filter list holding more conditions than the cap, with
+// every condition inside a group counted.
+func (fr FilterRules) tooMany(count int) error {
+	if count > fr.maxFilters() {
+		return fmt.Errorf("%w: at most %d filter conditions are allowed, got %d", ErrFilterNotAllowed, fr.maxFilters(), count)
+	}
+	return nil
+}
+
+var filterOperators = []string{
+	"eql", "neq", "ieq", "lik", "nlk", "stw", "enw",
+	"gt", "lt", "gte", "lte", "btw", "nbw", "nul", "nnu", "in", "nin",
+}
+
+var groupOperators = []string{"or", "and"}
+
+// likeEscape is used instead of a backslash because MySQL would need the
+// backslash doubled inside the ESCAPE literal and SQLite has no default.
+const likeEscape = "!"
+
+func (f Filter) IsGroup() bool {
+	return slices.Contains(groupOperators, f.Operator)
+}
+
+// String renders the filter back in the syntax it is parsed from.
+func (f Filter) String() string {
+	if !f.IsGroup() {
+		return f.Field + ":" + f.Operator + ":" + f.Value
+	}
+	parts := make([]string, len(f.Group))
+	for i, child := range f.Group {
+		parts[i] = child.String()
+	}
+	return f.Operator + "(" + strings.Join(parts, ",") + ")"
+}
+
+// conditions counts the column conditions in the filter.
+func (f Filter) conditions() int {
+	if !f.IsGroup() {
+		return 1
+	}
+	n := 0
+	for _, child := range f.Group {
+		n += child.conditions()
+	}
+	return n
+}
+
+// check validates the filter against the rules and, when schema is given,
+// parses its values as the column types and records the type.
+func (f *Filter) check(rules FilterRules, schema map[string]string) error {
+	if f.IsGroup() {
+		if len(f.Group) == 0 {
+			return errors.New("empty group")
 		}
+		for i := range f.Group {
+			if err := f.Group[i].check(rules, schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	operators, ok := rules.Columns[f.Field]
+	if !ok {
+		if _, known := schema[f.Field]; known {
+			return fmt.Errorf("%w: field %q is not filterable", ErrFilterNotAllowed, f.Field)
+		}
+		return fmt.Errorf("unknown field %q", f.Field)
+	}
+	if !slices.Contains(filterOperators, f.Operator) {
+		return fmt.Errorf("unsupported operator %q", f.Operator)
+	}
+	if len(operators) > 0 && !slices.Contains(operators, f.Operator) {
+		return fmt.Errorf("%w: operator %q is not allowed on %q", ErrFilterNotAllowed, f.Operator, f.Field)
+	}
 
-		field := strings.TrimSpace(parts[0])
-		operator := strings.TrimSpace(strings.ToLower(parts[1]))
-		value := strings.TrimSpace(parts[2])
+	var values []string
+	switch f.Operator {
+	case "btw", "nbw":
+		values = strings.Split(f.Value, ",")
+		if len(values) != 2 {
+			return fmt.Errorf("%s needs two comma-separated values", f.Operator)
+		}
+	case "in", "nin":
+		values = strings.Split(f.Value, ",")
+		if len(values) > rules.maxInValues() {
+			return fmt.Errorf("%w: %s takes at most %d values, got %d", ErrFilterNotAllowed, f.Operator, rules.maxInValues(), len(values))
+		}
+	case "eql", "neq", "gt", "lt", "gte", "lte":
+		values = []string{f.Value}
+	}
 
-		if _, ok := allowedMap[field]; ok {
-			filters = append(filters, Filter{
-				Field:    field,
-				Operator: operator,
-				Value:    value,
-			})
+	typ := strings.ToLower(schema[f.Field])
+	for _, v := range values {
+		if _, err := filterValue(typ, strings.TrimSpace(v)); err != nil {
+			return fmt.Errorf("%s: %w", f.Field, err)
 		}
 	}
+	if len(values) > 0 {
+		f.Type = typ
+	}
+	return nil
+}
+
+// FilterParams reads every filter param against the rules and the schema.
+// Filters that fail are left out and reported, one problem per param.
+func FilterParams(r *http.Request, rules FilterRules, schema map[string]string) ([]Filter, []QueryProblem) {
+	filters, problems, _ := filterParams(r, rules, schema)
+	return filters, problems
+}
+
+// filterParams also returns the error behind each problem, in step with it.
+func filterParams(r *http.Request, rules FilterRules, schema map[string]string) (filters []Filter, problems []QueryProblem, errs []error) {
+	report := func(raw string, err error) {
+		problems = append(problems, QueryProblem{Param: "filter", Value: raw, Message: err.Error()})
+		errs = append(errs, err)
+	}
 
+	count := 0
+	for _, raw := range r.URL.Query()["filter"] {
+		f, ok := parseExpression(raw)
+		if !ok {
+			report(raw, errors.New("malformed filter"))
+			continue
+		}
+		count += f.conditions()
+		if err := f.check(rules, schema); err != nil {
+			report(raw, err)
+			continue
+		}
+		filters = append(filters, f)
+	}
+	if err := rules.tooMany(count); err != nil {
+		report("", err)
+	}
+	return filters, problems, errs
+}
+
+// GetFilters reads every filter param and ANDs them together, skipping any
+// that names an unknown column or operator; a group with one bad condition
+// is skipped as a whole.
+func GetFilters(r *http.Request, allowed []string) []Filter {
+	filters, _ := FilterParams(r, AllowColumns(allowed), nil)
 	return filters
 }
 
-func BuildWhereClause(filters []Filter) (string, []interface{}) {
-	var clauses []string
-	var args []interface{}
+// Unlike GetFilters, which skips what it cannot use, ParseFilters rejects the
+// whole list so a bulk mutation never runs with a wider filter than requested.
+func ParseFilters(raw []string, rules FilterRules, schema map[string]string) ([]Filter, error) {
+	filters := make([]Filter, 0, len(raw))
+	count := 0
+	for _, r := range raw {
+		f, ok := parseExpression(r)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidFilter, r)
+		}
+		if err := f.check(rules, schema); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidFilter, r, err)
+		}
+		count += f.conditions()
+		filters = append(filters, f)
+	}
+	if err := rules.tooMany(count); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	return filters, nil
+}
 
-	for _, f := range filters {
-		escapadField := EscapeMysqlField(f.Field)
-		switch f.Operator {
-		case "eql":
-			clauses = append(clauses, fmt.Sprintf("%s = ?", escapadField))
-			args = append(args, f.Value)
-		case "neq":
-			clauses = append(clauses, fmt.Sprintf("%s != ?", escapadField))
-			args = append(args, f.Value)
-		case "lik":
-			clauses = append(clauses, fmt.Sprintf("%s LIKE ?", escapadField))
-			args = append(args, "%"+f.Value+"%")
-		case "gt":
-			clauses = append(clauses, fmt.Sprintf("%s > ?", escapadField))
-			args = append(args, f.Value)
-		case "lt":
-			clauses = append(clauses, fmt.Sprintf("%s < ?", escapadField))
-			args = append(args, f.Value)
-		case "gte":
-			clauses = append(clauses, fmt.Sprintf("%s >= ?", escapadField))
-			args = append(args, f.Value)
-		case "lte":
-			clauses = append(clauses, fmt.Sprintf("%s <= ?", escapadField))
-			args = append(args, f.Value)
-		case "btw":
-			rangeParts := strings.Split(f.Value, ",")
-			if len(rangeParts) == 2 {
-				clauses = append(clauses, fmt.Sprintf("%s BETWEEN ? AND ?", escapadField))
-				args = append(args, rangeParts[0], rangeParts[1])
+var filterTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// filterValue converts a raw filter value to the Go value bound for a column
+// of the given schema type; unknown types bind the raw string.
+func filterValue(typ, raw string) (interface{}, error) {
+	switch strings.TrimPrefix(typ, "*") {
+	case "int", "int64":
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return v, nil
+	case "uint", "uint64":
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an unsigned integer", raw)
+		}
+		return v, nil
+	case "float", "float32", "float64":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return v, nil
+	case "decimal":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return raw, nil
+	case "bool":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return v, nil
+	case "time.time":
+		for _, layout := range filterTimeLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t, nil
 			}
-		case "nul":
-			if f.Value == "true" {
-				clauses = append(clauses, fmt.Sprintf("%s IS NULL", escapadField))
-			} else {
-				clauses = append(clauses, fmt.Sprintf("%s IS NOT NULL", escapadField))
+		}
+		return nil, fmt.Errorf("%q is not a date (use YYYY-MM-DD, YYYY-MM-DD HH:MM:SS or RFC 3339)", raw)
+	}
+	return raw, nil
+}
+
+func (f Filter) arg(raw string) interface{} {
+	if v, err := filterValue(f.Type, raw); err == nil {
+		return v
+	}
+	return raw
+}
+
+func parseExpression(raw string) (Filter, bool) {
+	raw = strings.TrimSpace(raw)
+	op, body, ok := groupBody(raw)
+	if !ok {
+		return parseFilter(raw)
+	}
+
+	group := Filter{Operator: op}
+	for _, term := range splitTerms(body) {
+		child, ok := parseExpression(term)
+		if !ok {
+			return Filter{}, false
+		}
+		group.Group = append(group.Group, child)
+	}
+	return group, len(group.Group) > 0
+}
+
+// groupBody matches "or(...)" and "and(...)" whose opening parenthesis is
+// only closed by the final character.
+func groupBody(raw string) (string, string, bool) {
+	name, rest, ok := strings.Cut(raw, "(")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !ok || !slices.Contains(groupOperators, name) || !strings.HasSuffix(rest, ")") {
+		return "", "", false
+	}
+	body := rest[:len(rest)-1]
+	depth := 0
+	for _, c := range body {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return "", "", false
 			}
-		case "nnu":
-			clauses = append(clauses, fmt.Sprintf("%s IS NOT NULL", escapadField))
-		case "in":
-			inParts := strings.Split(f.Value, ",")
-			if len(inParts) > 0 {
-				placeholders := strings.Repeat("?,", len(inParts))
-				placeholders = strings.TrimRight(placeholders, ",")
-				clauses = append(clauses, fmt.Sprintf("%s IN (%s)", escapadField, placeholders))
-				for _, val := range inParts {
-					args = append(args, strings.TrimSpace(val))
-				}
+		}
+	}
+	return name, body, depth == 0
+}
+
+// splitTerms splits a group body on top-level commas. A piece that does not
+// start a condition or a group belongs to the previous value, which keeps
+// lists such as role:in:admin,user together.
+func splitTerms(body string) []string {
+	var terms []string
+	depth, start := 0, 0
+	flush := func(piece string) {
+		if len(terms) > 0 && !startsTerm(piece) {
+			terms[len(terms)-1] += "," + piece
+			return
+		}
+		terms = append(terms, piece)
+	}
+	for i, c := range body {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				flush(body[start:i])
+				start = i + 1
 			}
 		}
 	}
+	flush(body[start:])
+	return terms
+}
 
-	if len(clauses) == 0 {
+func startsTerm(piece string) bool {
+	if _, _, ok := groupBody(strings.TrimSpace(piece)); ok {
+		return true
+	}
+	f, ok := parseFilter(piece)
+	return ok && slices.Contains(filterOperators, f.Operator)
+}
+
+func parseFilter(raw string) (Filter, bool) {
+	parts := strings.SplitN(raw, ":", 3)
+	if len(parts) != 3 {
+		return Filter{}, false
+	}
+
+	return Filter{
+		Field:    strings.TrimSpace(parts[0]),
+		Operator: strings.TrimSpace(strings.ToLower(parts[1])),
+		Value:    strings.TrimSpace(parts[2]),
+	}, true
+}
+
+func BuildWhereClause(filters []Filter) (string, []interface{}) {
+	clause, args := joinConditions(CurrentDialect(), filters, " AND ")
+	if clause == "" {
 		return "", args
 	}
 
-	return "WHERE " + strings.Join(clauses, " AND "), args
+	return "WHERE " + clause, args
+}
+
+func joinConditions(d Dialect, filters []Filter, sep string) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for _, f := range filters {
+		clause, clauseArgs := filterCondition(d, f)
+		if clause != "" {
+			clauses = append(clauses, clause)
+			args = append(args, clauseArgs...)
+		}
+	}
+	return strings.Join(clauses, sep), args
+}
+
+func filterCondition(d Dialect, f Filter) (string, []interface{}) {
+	if f.IsGroup() {
+		clause, args := joinConditions(d, f.Group, " "+strings.ToUpper(f.Operator)+" ")
+		if clause == "" {
+			return "", nil
+		}
+		return "(" + clause + ")", args
+	}
+
+	if f.Operator == searchOperator {
+		return searchCondition(d, f)
+	}
+
+	escapadField := d.QuoteIdentifier(f.Field)
+	switch f.Operator {
+	case "eql":
+		return fmt.Sprintf("%s = ?", escapadField), []interface{}{f.arg(f.Value)}
+	case "neq":
+		return fmt.Sprintf("%s != ?", escapadField), []interface{}{f.arg(f.Value)}
+	case "ieq":
+		return fmt.Sprintf("LOWER(%s) = LOWER(?)", escapadField), []interface{}{f.Value}
+	case "lik":
+		return likeCondition(escapadField, "LIKE", "%"+EscapeLike(f.Value)+"%")
+	case "nlk":
+		return likeCondition(escapadField, "NOT LIKE", "%"+EscapeLike(f.Value)+"%")
+	case "stw":
+		return likeCondition(escapadField, "LIKE", EscapeLike(f.Value)+"%")
+	case "enw":
+		return likeCondition(escapadField, "LIKE", "%"+EscapeLike(f.Value))
+	case "gt":
+		return fmt.Sprintf("%s > ?", escapadField), []interface{}{f.arg(f.Value)}
+	case "lt":
+		return fmt.Sprintf("%s < ?", escapadField), []interface{}{f.arg(f.Value)}
+	case "gte":
+		return fmt.Sprintf("%s >= ?", escapadField), []interface{}{f.arg(f.Value)}
+	case "lte":
+		return fmt.Sprintf("%s <= ?", escapadField), []interface{}{f.arg(f.Value)}
+	case "btw", "nbw":
+		rangeParts := strings.Split(f.Value, ",")
+		if len(rangeParts) != 2 {
+			return "", nil
+		}
+		keyword := "BETWEEN"
+		if f.Operator == "nbw" {
+			keyword = "NOT BETWEEN"
+		}
+		return fmt.Sprintf("%s %s ? AND ?", escapadField, keyword), []interface{}{f.arg(strings.TrimSpace(rangeParts[0])), f.arg(strings.TrimSpace(rangeParts[1]))}
+	case "nul":
+		if f.Value == "true" {
+			return fmt.Sprintf("%s IS NULL", escapadField), nil
+		}
+		return fmt.Sprintf("%s IS NOT NULL", escapadField), nil
+	case "nnu":
+		return fmt.Sprintf("%s IS NOT NULL", escapadField), nil
+	case "in", "nin":
+		inParts := strings.Split(f.Value, ",")
+		placeholders := strings.TrimRight(strings.Repeat("?,", len(inParts)), ",")
+		keyword := "IN"
+		if f.Operator == "nin" {
+			keyword = "NOT IN"
+		}
+		args := make([]interface{}, len(inParts))
+		for i, val := range inParts {
+			args[i] = f.arg(strings.TrimSpace(val))
+		}
+		return fmt.Sprintf("%s %s (%s)", escapadField, keyword, placeholders), args
+	}
+	return "", nil
+}
+
+func likeCondition(col, keyword, pattern string) (string, []interface{}) {
+	return fmt.Sprintf("%s %s ? ESCAPE '%s'", col, keyword, likeEscape), []interface{}{pattern}
+}
+
+// EscapeLike makes the LIKE wildcards in user input match literally.
+func EscapeLike(s string) string {
+	return strings.NewReplacer(likeEscape, likeEscape+likeEscape, "%", likeEscape+"%", "_", likeEscape+"_").Replace(s)
 }
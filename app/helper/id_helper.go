@@ -0,0 +1,136 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/not-empty/ulid-go-lib"
+)
+
+var (
+	ErrIDRequired = errors.New("id is required")
+	ErrInvalidID  = errors.New("malformed id")
+)
+
+type IDStrategy interface {
+	NewID() (string, error)
+	ValidateID(id string) error
+}
+
+type ULIDStrategy struct {
+	Gen ulid.Generator
+}
+
+func (s ULIDStrategy) NewID() (string, error) {
+	gen := s.Gen
+	if gen == nil {
+		gen = ulid.NewDefaultGenerator()
+	}
+	return gen.Generate(0)
+}
+
+func (ULIDStrategy) ValidateID(id string) error {
+	if len(id) != ulid.TIME_LENGTH+ulid.RANDOM_LENGTH || id[0] > '7' {
+		return ErrInvalidID
+	}
+	for _, c := range strings.ToUpper(id) {
+		if !strings.ContainsRune(ulid.CHARS, c) {
+			return ErrInvalidID
+		}
+	}
+	return nil
+}
+
+type UUIDv4Strategy struct{}
+
+func (UUIDv4Strategy) NewID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return formatUUID(b, 4), nil
+}
+
+func (UUIDv4Strategy) ValidateID(id string) error {
+	return validateUUID(id, '4')
+}
+
+type UUIDv7Strategy struct{}
+
+func (UUIDv7Strategy) NewID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(b[:6], ms[2:])
+	return formatUUID(b, 7), nil
+}
+
+func (UUIDv7Strategy) ValidateID(id string) error {
+	return validateUUID(id, '7')
+}
+
+// The database assigns the id, so NewID returns an empty one and the
+// repository reads it back after the insert.
+type AutoIncrementStrategy struct{}
+
+func (AutoIncrementStrategy) NewID() (string, error) {
+	return "", nil
+}
+
+func (AutoIncrementStrategy) ValidateID(id string) error {
+	if n, err := strconv.ParseInt(id, 10, 64); err != nil || n <= 0 {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+type ClientIDStrategy struct{}
+
+func (ClientIDStrategy) NewID() (string, error) {
+	return "", ErrIDRequired
+}
+
+func (ClientIDStrategy) ValidateID(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return ErrIDRequired
+	}
+	return nil
+}
+
+func formatUUID(b [16]byte, version byte) string {
+	b[6] = b[6]&0x0f | version<<4
+	b[8] = b[8]&0x3f | 0x80
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+func validateUUID(id string, version byte) error {
+	if len(id) != 36 || id[8] != '-' || id[13] != '-' || id[18] != '-' || id[23] != '-' {
+		return ErrInvalidID
+	}
+	if _, err := hex.DecodeString(strings.ReplaceAll(id, "-", "")); err != nil {
+		return ErrInvalidID
+	}
+	if id[14] != version || !strings.ContainsRune("89abAB", rune(id[19])) {
+		return ErrInvalidID
+	}
+	return nil
+}
@@ -2,9 +2,20 @@ package helper
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 )
 
+// SortKey is one column of a sort and its direction.
+type SortKey struct {
+	Column string
+	Order  string
+}
+
+func (k SortKey) String() string {
+	return k.Column + ":" + k.Order
+}
+
 func GetOrderParams(r *http.Request, defaultColumn string) (orderBy string, orderDir string) {
 	query := r.URL.Query()
 
@@ -20,3 +31,63 @@ func GetOrderParams(r *http.Request, defaultColumn string) (orderBy string, orde
 
 	return
 }
+
+// SortTerms splits an order_by such as "status:asc,created_at:desc" into
+// its terms as written. A term without a direction has an empty Order.
+func SortTerms(orderBy string) []SortKey {
+	var terms []SortKey
+	for _, term := range strings.Split(orderBy, ",") {
+		col, dir, _ := strings.Cut(strings.TrimSpace(term), ":")
+		if col = strings.TrimSpace(col); col != "" {
+			terms = append(terms, SortKey{Column: col, Order: strings.ToUpper(strings.TrimSpace(dir))})
+		}
+	}
+	return terms
+}
+
+// ParseSort resolves an order_by into the complete order rows are read in.
+// Terms on unknown columns are dropped and those without a valid direction
+// take order. The key columns follow as tiebreakers in order's direction,
+// and anything after the last of them is cut, since it can no longer change
+// the order.
+func ParseSort(orderBy, order string, allowed, keys []string) []SortKey {
+	order = ValidateOrder(order)
+
+	var sort []SortKey
+	has := func(col string) bool {
+		return slices.ContainsFunc(sort, func(k SortKey) bool { return k.Column == col })
+	}
+	for _, term := range SortTerms(orderBy) {
+		if !slices.Contains(allowed, term.Column) || has(term.Column) {
+			continue
+		}
+		if term.Order != "ASC" && term.Order != "DESC" {
+			term.Order = order
+		}
+		sort = append(sort, term)
+	}
+	for _, key := range keys {
+		if !has(key) {
+			sort = append(sort, SortKey{Column: key, Order: order})
+		}
+	}
+
+	seen := 0
+	for i, k := range sort {
+		if slices.Contains(keys, k.Column) {
+			if seen++; seen == len(keys) {
+				return sort[:i+1]
+			}
+		}
+	}
+	return sort
+}
+
+// SortString renders a resolved sort, e.g. "status:ASC,id:DESC".
+func SortString(sort []SortKey) string {
+	parts := make([]string, len(sort))
+	for i, k := range sort {
+		parts[i] = k.String()
+	}
+	return strings.Join(parts, ",")
+}
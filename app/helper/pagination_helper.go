@@ -1,39 +1,176 @@
 package helper
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
-const DefaultPageLimit = 25
+const (
+	DefaultPageLimit = 25
+	DefaultMaxOffset = 10000
+)
+
+var (
+	ErrInvalidPage    = errors.New("page and offset must be non-negative integers")
+	ErrOffsetTooLarge = errors.New("offset exceeds the maximum for this domain")
+	ErrPageWithCursor = errors.New("page and offset cannot be combined with page_cursor")
+	ErrInvalidCursor  = errors.New("invalid page cursor")
+)
 
+// PageCursor marks the row a keyset page stopped at together with the query
+// it came from, so a cursor cannot be replayed against a different order or
+// filter set. Values holds the row's value for each column of the resolved
+// sort, in its order, keeping their JSON types; a nil value is a NULL. Times
+// lists the values that are timestamps, carried as RFC3339Nano so they resume
+// at full precision and bind back as time.Time.
 type PageCursor struct {
-	LastID    string `json:"last_id"`
-	LastValue string `json:"last_value"`
+	Values   []any  `json:"v"`
+	Times    []int  `json:"t,omitempty"`
+	OrderBy  string `json:"o"`
+	Filters  string `json:"f,omitempty"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+var cursorSecret = randomSecret()
+
+func randomSecret() []byte {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return b
+}
+
+// SetCursorSecret sets the key cursors are signed with. Until it is called
+// with a non-empty secret a per-process random key is used, so cursors do not
+// survive a restart or move between instances.
+func SetCursorSecret(secret string) {
+	if secret != "" {
+		cursorSecret = []byte(secret)
+	}
+}
+
+func NewPageCursor(row map[string]any, sort []SortKey, filters []Filter, backward bool) PageCursor {
+	values := make([]any, len(sort))
+	var times []int
+	for i, k := range sort {
+		values[i] = row[k.Column]
+		if t, ok := values[i].(JSONTime); ok {
+			values[i] = time.Time(t).Format(time.RFC3339Nano)
+			times = append(times, i)
+		}
+	}
+	return PageCursor{
+		Values:   values,
+		Times:    times,
+		OrderBy:  SortString(sort),
+		Filters:  FiltersDigest(filters),
+		Backward: backward,
+	}
+}
+
+// Matches reports whether the cursor was issued for the given query shape.
+func (c PageCursor) Matches(sort []SortKey, filters []Filter) error {
+	if c.OrderBy != SortString(sort) || c.Filters != FiltersDigest(filters) {
+		return fmt.Errorf("%w: issued for a different order or filters", ErrInvalidCursor)
+	}
+	if len(c.Values) != len(sort) {
+		return fmt.Errorf("%w: must hold %d values", ErrInvalidCursor, len(sort))
+	}
+	return nil
+}
+
+// FiltersDigest fingerprints a filter set independently of its order.
+func FiltersDigest(filters []Filter) string {
+	if len(filters) == 0 {
+		return ""
+	}
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = f.String()
+	}
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return cursorEncoding.EncodeToString(sum[:12])
 }
 
+var cursorEncoding = base64.URLEncoding.WithPadding(base64.NoPadding)
+
 func EncodeCursor(c PageCursor) string {
 	data, _ := json.Marshal(c)
-	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(data)
+	payload := cursorEncoding.EncodeToString(data)
+	return payload + "." + cursorEncoding.EncodeToString(signCursor(payload))
 }
 
 func DecodeCursor(token string) (PageCursor, error) {
 	var c PageCursor
-	raw, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(token)
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return c, fmt.Errorf("%w: malformed token", ErrInvalidCursor)
+	}
+	rawSig, err := cursorEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(rawSig, signCursor(payload)) {
+		return c, fmt.Errorf("%w: bad signature", ErrInvalidCursor)
+	}
+	raw, err := cursorEncoding.DecodeString(payload)
 	if err != nil {
-		return c, errors.New("invalid cursor encoding")
+		return c, fmt.Errorf("%w: bad encoding", ErrInvalidCursor)
 	}
-	if err := json.Unmarshal(raw, &c); err != nil {
-		return c, errors.New("invalid cursor payload")
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil {
+		return c, fmt.Errorf("%w: bad payload", ErrInvalidCursor)
+	}
+	for i, v := range c.Values {
+		c.Values[i] = cursorValue(v)
+	}
+	for _, i := range c.Times {
+		if i < 0 || i >= len(c.Values) {
+			return c, fmt.Errorf("%w: bad payload", ErrInvalidCursor)
+		}
+		raw, _ := c.Values[i].(string)
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return c, fmt.Errorf("%w: bad time value", ErrInvalidCursor)
+		}
+		c.Values[i] = t
 	}
 	return c, nil
 }
 
+func signCursor(payload string) []byte {
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// cursorValue turns decoded numbers back into integers where they fit, so
+// they bind as numbers rather than as float or text parameters.
+func cursorValue(v any) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if u, err := strconv.ParseUint(n.String(), 10, 64); err == nil {
+		return u
+	}
+	f, _ := n.Float64()
+	return f
+}
+
 func GetPaginationParams(r *http.Request) (limit int, cursor *PageCursor, err error) {
 	limit = ParseLimit(r.URL.Query().Get("limit"))
 
@@ -42,51 +179,32 @@ func GetPaginationParams(r *http.Request) (limit int, cursor *PageCursor, err er
 		return
 	}
 
-	var c PageCursor
-	rawBytes, decErr := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw)
-	if decErr != nil {
-		err = errors.New("Invalid cursor encoding")
-		return
-	}
-	if jsonErr := json.Unmarshal(rawBytes, &c); jsonErr != nil {
-		err = errors.New("Invalid cursor payload")
+	c, err := DecodeCursor(raw)
+	if err != nil {
 		return
 	}
 	cursor = &c
 	return
 }
 
-func BuildPageCursor(body []byte, query url.Values) (string, error) {
-	if len(body) == 0 || body[0] != '[' {
-		return "", nil
-	}
-
-	var arr []map[string]interface{}
-	if err := json.Unmarshal(body, &arr); err != nil || len(arr) == 0 {
-		return "", nil
-	}
-
-	lim := ParseLimit(query.Get("limit"))
-	if len(arr) < lim {
-		return "", nil
+// PageCursors builds the cursors for a page that has already been read. The
+// next cursor resumes after the last row and is left out once a forward read
+// comes back short; the previous cursor walks back from the first row and is
+// only issued when a page was reached through a cursor.
+func PageCursors(rows []map[string]any, limit int, current *PageCursor, sort []SortKey, filters []Filter) (next, prev string) {
+	if len(rows) == 0 {
+		return "", ""
 	}
+	backward := current != nil && current.Backward
+	full := len(rows) >= limit
 
-	last := arr[len(arr)-1]
-	orderBy := query.Get("order_by")
-	if orderBy == "" {
-		orderBy = "id"
+	if full || backward {
+		next = EncodeCursor(NewPageCursor(rows[len(rows)-1], sort, filters, false))
 	}
-
-	idVal, _ := last["id"].(string)
-	var lastVal string
-	if v, ok := last[orderBy]; ok {
-		lastVal = fmt.Sprintf("%v", v)
+	if current != nil && (full || !backward) {
+		prev = EncodeCursor(NewPageCursor(rows[0], sort, filters, true))
 	}
-
-	return EncodeCursor(PageCursor{
-		LastID:    idVal,
-		LastValue: lastVal,
-	}), nil
+	return next, prev
 }
 
 func ParseLimit(raw string) int {
@@ -100,3 +218,42 @@ func ParseLimit(raw string) int {
 	}
 	return v
 }
+
+// GetOffsetParams reads the page-number mode: ?page= counts limit-sized pages
+// from 1 and ?offset= skips rows directly. Offsets past maxOffset are
+// refused because the database still walks every skipped row.
+func GetOffsetParams(r *http.Request, limit, maxOffset int, cursor *PageCursor) (offset int, err error) {
+	query := r.URL.Query()
+	if !query.Has("page") && !query.Has("offset") {
+		return 0, nil
+	}
+	if cursor != nil {
+		return 0, ErrPageWithCursor
+	}
+
+	if raw := query.Get("page"); query.Has("page") {
+		page, convErr := strconv.Atoi(raw)
+		if convErr != nil || page < 1 {
+			return 0, ErrInvalidPage
+		}
+		offset = (page - 1) * limit
+	} else {
+		offset, err = strconv.Atoi(query.Get("offset"))
+		if err != nil || offset < 0 {
+			return 0, ErrInvalidPage
+		}
+	}
+
+	if maxOffset <= 0 {
+		maxOffset = DefaultMaxOffset
+	}
+	if offset > maxOffset {
+		return 0, ErrOffsetTooLarge
+	}
+	return offset, nil
+}
+
+func WantsTotal(r *http.Request) bool {
+	v, _ := strconv.ParseBool(r.URL.Query().Get("with_total"))
+	return v
+}
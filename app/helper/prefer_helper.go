@@ -0,0 +1,22 @@
+package helper
+
+import (
+	"net/http"
+	"strings"
+)
+
+const PreferRepresentation = "return=representation"
+
+func PrefersRepresentation(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			pref, _, _ = strings.Cut(pref, ";")
+			name, value, _ := strings.Cut(strings.TrimSpace(pref), "=")
+			if strings.EqualFold(strings.TrimSpace(name), "return") &&
+				strings.EqualFold(strings.Trim(strings.TrimSpace(value), `"`), "representation") {
+				return true
+			}
+		}
+	}
+	return false
+}
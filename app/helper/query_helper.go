@@ -0,0 +1,125 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// QueryProblem is one query parameter a strict request was rejected for.
+type QueryProblem struct {
+	Param   string `json:"param"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+}
+
+type QueryErrorResponse struct {
+	Error    string         `json:"error"`
+	Problems []QueryProblem `json:"problems"`
+}
+
+// QueryRules is what a domain lets clients select, sort and filter on.
+type QueryRules struct {
+	Fields   []string
+	Sortable []string
+	Filters  FilterRules
+}
+
+func (qr QueryRules) sortProblem(col, orderBy string, schema map[string]string) *QueryProblem {
+	if slices.Contains(qr.Sortable, col) {
+		return nil
+	}
+	if _, known := schema[col]; known {
+		return &QueryProblem{Param: "order_by", Value: orderBy, Message: fmt.Sprintf("field %q is not sortable", col)}
+	}
+	return &QueryProblem{Param: "order_by", Value: orderBy, Message: fmt.Sprintf("unknown field %q", col)}
+}
+
+// CheckQueryLimits lists the order_by and filter params that name a column
+// the domain does not allow sorting or filtering on, or that exceed its
+// caps. Unlike the other problems these are never skipped over, since doing
+// so would quietly widen the result.
+func CheckQueryLimits(r *http.Request, rules QueryRules, schema map[string]string) []QueryProblem {
+	query := r.URL.Query()
+	var problems []QueryProblem
+
+	orderBy := query.Get("order_by")
+	for _, term := range SortTerms(orderBy) {
+		if _, known := schema[term.Column]; known {
+			if p := rules.sortProblem(term.Column, orderBy, schema); p != nil {
+				problems = append(problems, *p)
+			}
+		}
+	}
+
+	_, filterProblems, errs := filterParams(r, rules.Filters, schema)
+	for i, err := range errs {
+		if errors.Is(err, ErrFilterNotAllowed) {
+			problems = append(problems, filterProblems[i])
+		}
+	}
+	return problems
+}
+
+// CheckQueryParams lists every problem with the order_by, order, limit,
+// fields and filter params of a request, instead of the silent fallbacks
+// the lenient readers apply.
+func CheckQueryParams(r *http.Request, rules QueryRules, schema map[string]string) []QueryProblem {
+	query := r.URL.Query()
+	var problems []QueryProblem
+
+	if query.Has("order_by") {
+		orderBy := query.Get("order_by")
+		terms := SortTerms(orderBy)
+		if len(terms) == 0 {
+			problems = append(problems, QueryProblem{Param: "order_by", Value: orderBy, Message: "must name at least one field"})
+		}
+		for _, term := range terms {
+			if p := rules.sortProblem(term.Column, orderBy, schema); p != nil {
+				problems = append(problems, *p)
+			}
+			if term.Order != "" && term.Order != "ASC" && term.Order != "DESC" {
+				problems = append(problems, QueryProblem{Param: "order_by", Value: orderBy, Message: fmt.Sprintf("direction of %q must be asc or desc", term.Column)})
+			}
+		}
+	}
+
+	if query.Has("order") {
+		if v := query.Get("order"); !slices.Contains([]string{"ASC", "DESC"}, strings.ToUpper(v)) {
+			problems = append(problems, QueryProblem{Param: "order", Value: v, Message: "must be asc or desc"})
+		}
+	}
+
+	if query.Has("limit") {
+		v := query.Get("limit")
+		if n, err := strconv.Atoi(v); err != nil || n < 1 || n > DefaultPageLimit {
+			problems = append(problems, QueryProblem{Param: "limit", Value: v, Message: fmt.Sprintf("must be an integer from 1 to %d", DefaultPageLimit)})
+		}
+	}
+
+	if query.Has("fields") {
+		v := query.Get("fields")
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); !slices.Contains(rules.Fields, field) {
+				problems = append(problems, QueryProblem{Param: "fields", Value: v, Message: fmt.Sprintf("unknown field %q", field)})
+			}
+		}
+	}
+
+	_, filterProblems := FilterParams(r, rules.Filters, schema)
+	return append(problems, filterProblems...)
+}
+
+func JSONQueryProblems(w http.ResponseWriter, problems []QueryProblem) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	_ = json.NewEncoder(w).Encode(QueryErrorResponse{
+		Error:    "Invalid query",
+		Problems: problems,
+	})
+}
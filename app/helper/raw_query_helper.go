@@ -3,6 +3,7 @@ package helper
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -10,6 +11,7 @@ import (
 var (
 	rawMu      sync.RWMutex
 	rawQueries = make(map[string]map[string]string)
+	rawParamRE = regexp.MustCompile(`::?[A-Za-z0-9_]+`)
 )
 
 func RegisterRawQueries(table string, queries map[string]string) {
@@ -101,12 +103,14 @@ func CheckRawQueryAllowed(query string) (bool, error) {
 }
 
 func ExtractRawParams(query string) []string {
-	re := regexp.MustCompile(`:([A-Za-z0-9_]+)`)
-	matches := re.FindAllStringSubmatch(query, -1)
+	matches := rawParamRE.FindAllString(query, -1)
 	seen := map[string]bool{}
 	var params []string
 	for _, m := range matches {
-		name := m[1]
+		if strings.HasPrefix(m, "::") {
+			continue
+		}
+		name := m[1:]
 		if !seen[name] {
 			seen[name] = true
 			params = append(params, name)
@@ -135,16 +139,17 @@ func ValidateRawParams(query string, params map[string]any) error {
 }
 
 func PrepareRawQuery(query string, params map[string]any) (string, []interface{}) {
-	re := regexp.MustCompile(`:([A-Za-z0-9_]+)`)
-	matches := re.FindAllStringSubmatch(query, -1)
-
-	args := make([]interface{}, 0, len(matches))
-	for _, match := range matches {
-		key := match[1]
-		query = strings.Replace(query, ":"+key, "?", 1)
-		args = append(args, params[key])
-	}
+	d := CurrentDialect()
+
+	args := make([]interface{}, 0)
+	query = rawParamRE.ReplaceAllStringFunc(query, func(match string) string {
+		if strings.HasPrefix(match, "::") {
+			return match
+		}
+		args = append(args, params[match[1:]])
+		return d.Placeholder(len(args))
+	})
 
-	query = fmt.Sprintf("%s LIMIT %d", query, 25)
+	query = fmt.Sprintf("%s %s", query, d.Limit(strconv.Itoa(25), ""))
 	return query, args
 }
@@ -3,10 +3,14 @@ package helper
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
+const KeySeparator = "/"
+
 func JSONResponse(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -21,6 +25,56 @@ func ExtractID(path, prefix string) (string, error) {
 	return id, nil
 }
 
+func ParseKey(raw string, cols []string, schema map[string]string) ([]interface{}, error) {
+	parts := []string{raw}
+	if len(cols) > 1 {
+		parts = strings.Split(raw, KeySeparator)
+		if len(parts) != len(cols) {
+			return nil, fmt.Errorf("ID must have %d parts", len(cols))
+		}
+	}
+
+	vals := make([]interface{}, len(cols))
+	for i, col := range cols {
+		if parts[i] == "" {
+			return nil, errors.New("Missing ID")
+		}
+		v, err := keyValue(strings.ToLower(schema[col]), parts[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s", col)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// keyValue converts a key part to the value scanned for a column of the given
+// schema type: int64 for signed integers, uint64 for unsigned ones.
+func keyValue(typ, raw string) (interface{}, error) {
+	switch strings.TrimPrefix(typ, "*") {
+	case "int", "int64":
+		return strconv.ParseInt(raw, 10, 64)
+	case "uint", "uint64":
+		return strconv.ParseUint(raw, 10, 64)
+	}
+	return raw, nil
+}
+
+func JoinKey(vals []interface{}) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, KeySeparator)
+}
+
+func KeyString(v any) string {
+	if vals, ok := v.([]interface{}); ok {
+		return JoinKey(vals)
+	}
+	return fmt.Sprint(v)
+}
+
 func FilterList[T any](list []T, fields []string) []map[string]interface{} {
 	filtered := make([]map[string]interface{}, 0, len(list))
 	for _, item := range list {
@@ -2,6 +2,7 @@ package helper
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -67,30 +68,14 @@ func GenericScanToMap(
 	scanArgs := make([]any, len(cols))
 
 	for i, col := range cols {
-		typ, ok := schema[col]
+		ptr, ok := scanTarget(schema[col])
 		if !ok {
 			var discard any
 			scanArgs[i] = &discard
 			continue
 		}
-
-		switch strings.ToLower(typ) {
-		case "string":
-			ptr := new(sql.NullString)
-			scanMap[col] = ptr
-			scanArgs[i] = ptr
-		case "int":
-			ptr := new(sql.NullInt64)
-			scanMap[col] = ptr
-			scanArgs[i] = ptr
-		case "*time.time", "time.time":
-			ptr := new(sql.NullTime)
-			scanMap[col] = ptr
-			scanArgs[i] = ptr
-		default:
-			var discard any
-			scanArgs[i] = &discard
-		}
+		scanMap[col] = ptr
+		scanArgs[i] = ptr
 	}
 
 	if err := scanner.Scan(scanArgs...); err != nil {
@@ -103,39 +88,92 @@ func GenericScanToMap(
 		if !ok {
 			continue
 		}
-		switch v := raw.(type) {
-		case *sql.NullString:
-			if v.Valid {
-				result[col] = v.String
-			} else {
-				result[col] = ""
-			}
-		case *sql.NullInt64:
-			if v.Valid {
-				result[col] = int(v.Int64)
-			} else {
-				result[col] = 0
-			}
-		case *sql.NullTime:
-			if v.Valid {
-				dbType := ""
-				if i < len(colTypes) {
-					dbType = strings.ToUpper(colTypes[i].DatabaseTypeName())
-				}
-				if dbType == "DATE" {
-					result[col] = v.Time.Format("2006-01-02")
-				} else {
-					result[col] = v.Time.Format("2006-01-02 15:04:05")
-				}
-			} else {
-				result[col] = nil
-			}
+		dbType := ""
+		if i < len(colTypes) {
+			dbType = strings.ToUpper(colTypes[i].DatabaseTypeName())
 		}
+		result[col] = scannedValue(raw, strings.ToLower(schema[col]), dbType)
 	}
 
 	return result, nil
 }
 
+// scanTarget returns the destination GenericScanToMap scans a column of the
+// given schema type into, or false when the type is not supported.
+func scanTarget(typ string) (any, bool) {
+	switch strings.ToLower(typ) {
+	case "string", "*string", "decimal", "*decimal":
+		return new(sql.NullString), true
+	case "int", "*int", "int64", "*int64":
+		return new(sql.NullInt64), true
+	case "uint64", "*uint64", "uint", "*uint":
+		return new(sql.Null[uint64]), true
+	case "float64", "*float64", "float32", "*float32", "float":
+		return new(sql.NullFloat64), true
+	case "bool", "*bool":
+		return new(sql.NullBool), true
+	case "json", "json.rawmessage", "[]byte", "bytes", "binary":
+		return new([]byte), true
+	case "*time.time", "time.time":
+		return new(sql.NullTime), true
+	}
+	return nil, false
+}
+
+func scannedValue(raw any, typ, dbType string) any {
+	switch v := raw.(type) {
+	case *sql.NullString:
+		if !v.Valid {
+			return nil
+		}
+		return v.String
+	case *sql.NullInt64:
+		if !v.Valid {
+			return nil
+		}
+		return int(v.Int64)
+	case *sql.Null[uint64]:
+		if !v.Valid {
+			return nil
+		}
+		return v.V
+	case *sql.NullFloat64:
+		if !v.Valid {
+			return nil
+		}
+		return v.Float64
+	case *sql.NullBool:
+		if !v.Valid {
+			return nil
+		}
+		return v.Bool
+	case *[]byte:
+		if *v == nil {
+			return nil
+		}
+		if typ == "json" || typ == "json.rawmessage" {
+			// A driver handing back something that is not JSON would make
+			// the whole response unencodable, so it degrades to a string.
+			if json.Valid(*v) {
+				return json.RawMessage(*v)
+			}
+			return string(*v)
+		}
+		return *v
+	case *sql.NullTime:
+		if !v.Valid {
+			return nil
+		}
+		if dbType == "DATE" {
+			return v.Time.Format("2006-01-02")
+		}
+		// JSONTime renders the usual seconds but keeps the full value, which
+		// page cursors and bound parameters need.
+		return JSONTime(v.Time)
+	}
+	return nil
+}
+
 func MapKeys(m map[string]string) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -161,14 +199,66 @@ func SimpleScanRows(rs RowScanner) ([]map[string]any, error) {
 
 		rowMap := make(map[string]any, len(cols))
 		for i, col := range cols {
-			v := values[i]
-			switch x := v.(type) {
-			case nil:
-				rowMap[col] = nil
-			case []byte:
-				rowMap[col] = string(x)
-			default:
-				rowMap[col] = x
+			rowMap[col] = looseValue(values[i])
+		}
+		results = append(results, rowMap)
+	}
+
+	if err := rs.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// SchemaScanRows types every column named in schema like GenericScanToMap
+// does. Columns the schema does not know, such as aliases and aggregates in
+// raw queries, are typed from the driver's database type name when it maps
+// onto the schema vocabulary and are otherwise returned as SimpleScanRows
+// would return them.
+func SchemaScanRows(rs RowScanner, schema map[string]string) ([]map[string]any, error) {
+	cols, err := rs.Columns()
+	if err != nil {
+		return nil, err
+	}
+	colTypes, _ := rs.ColumnTypes()
+
+	types := make([]string, len(cols))
+	dbTypes := make([]string, len(cols))
+	for i, col := range cols {
+		if i < len(colTypes) {
+			dbTypes[i] = strings.ToUpper(colTypes[i].DatabaseTypeName())
+		}
+		typ, ok := schema[col]
+		if !ok {
+			typ = ColumnSchemaType(dbTypes[i])
+		}
+		types[i] = strings.ToLower(typ)
+	}
+
+	var results []map[string]any
+	for rs.Next() {
+		values := make([]any, len(cols))
+		scanArgs := make([]any, len(cols))
+		typed := make([]bool, len(cols))
+		for i := range cols {
+			if ptr, ok := scanTarget(types[i]); ok {
+				scanArgs[i] = ptr
+				typed[i] = true
+			} else {
+				scanArgs[i] = &values[i]
+			}
+		}
+
+		if err := rs.Scan(scanArgs...); err != nil {
+			return nil, err
+		}
+
+		rowMap := make(map[string]any, len(cols))
+		for i, col := range cols {
+			if typed[i] {
+				rowMap[col] = scannedValue(scanArgs[i], types[i], dbTypes[i])
+			} else {
+				rowMap[col] = looseValue(values[i])
 			}
 		}
 		results = append(results, rowMap)
@@ -179,3 +269,30 @@ func SimpleScanRows(rs RowScanner) ([]map[string]any, error) {
 	}
 	return results, nil
 }
+
+// ColumnSchemaType maps a driver database type name onto the schema type
+// vocabulary, returning "" for types that have no dedicated handling.
+func ColumnSchemaType(dbType string) string {
+	switch strings.ToUpper(dbType) {
+	case "JSON", "JSONB":
+		return "json"
+	case "DECIMAL", "NUMERIC":
+		return "decimal"
+	case "FLOAT", "DOUBLE", "REAL", "FLOAT4", "FLOAT8":
+		return "float64"
+	case "BOOL", "BOOLEAN":
+		return "bool"
+	case "UNSIGNED BIGINT":
+		return "uint64"
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY", "BYTEA":
+		return "[]byte"
+	}
+	return ""
+}
+
+func looseValue(v any) any {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
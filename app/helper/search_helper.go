@@ -0,0 +1,79 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RelevanceColumn is the name order_by and the response use for the search
+// score of a row.
+const RelevanceColumn = "relevance"
+
+const searchOperator = "search"
+
+// FullTextDialect is implemented by dialects that can match through a
+// full-text index. Match returns an expression binding the query once, that
+// is zero when a row does not match and its relevance otherwise.
+type FullTextDialect interface {
+	Match(quotedCols []string) string
+}
+
+func (MySQLDialect) Match(quotedCols []string) string {
+	return fmt.Sprintf("MATCH (%s) AGAINST (? IN NATURAL LANGUAGE MODE)", strings.Join(quotedCols, ", "))
+}
+
+// SearchFilter is the filter a q param becomes. Carrying the search among the
+// filters makes counts and cursors account for it like any other filter.
+func SearchFilter(columns []string, q string) Filter {
+	return Filter{Field: strings.Join(columns, ","), Operator: searchOperator, Value: strings.TrimSpace(q)}
+}
+
+// FindSearch returns the search among filters, if there is one.
+func FindSearch(filters []Filter) (Filter, bool) {
+	for _, f := range filters {
+		if f.Operator == searchOperator {
+			return f, true
+		}
+	}
+	return Filter{}, false
+}
+
+// RelevanceExpression scores a row against the search: the full-text score
+// where the dialect has one, otherwise how many of the columns contain q.
+func RelevanceExpression(d Dialect, search Filter) (string, []interface{}) {
+	cols := QuoteIdentifiers(d, strings.Split(search.Field, ","))
+	if ft, ok := d.(FullTextDialect); ok {
+		return ft.Match(cols), []interface{}{search.Value}
+	}
+
+	parts := make([]string, len(cols))
+	args := make([]interface{}, len(cols))
+	for i, col := range cols {
+		cond, condArgs := searchLike(col, search.Value)
+		parts[i] = "CASE WHEN " + cond + " THEN 1 ELSE 0 END"
+		args[i] = condArgs[0]
+	}
+	return "(" + strings.Join(parts, " + ") + ")", args
+}
+
+func searchCondition(d Dialect, search Filter) (string, []interface{}) {
+	if _, ok := d.(FullTextDialect); ok {
+		return RelevanceExpression(d, search)
+	}
+
+	cols := QuoteIdentifiers(d, strings.Split(search.Field, ","))
+	parts := make([]string, len(cols))
+	var args []interface{}
+	for i, col := range cols {
+		cond, condArgs := searchLike(col, search.Value)
+		parts[i] = cond
+		args = append(args, condArgs...)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// searchLike matches q anywhere in the column, ignoring case as MySQL's
+// default collations do.
+func searchLike(col, q string) (string, []interface{}) {
+	return likeCondition("LOWER("+col+")", "LIKE", "%"+EscapeLike(strings.ToLower(q))+"%")
+}
@@ -8,7 +8,7 @@ import (
 func CorsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		accessMethods := "POST, GET, OPTIONS, PUT, DELETE, PATCH"
-		accessHeaders := "Content-Type, Accept, Accept-Language, Authorization, X-Requested-With, Context, Suffix"
+		accessHeaders := "Content-Type, Accept, Accept-Language, Authorization, X-Requested-With, Context, Suffix, If-Match"
 
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", accessMethods)
@@ -81,6 +81,7 @@ func JwtMiddleware(next http.Handler) http.Handler {
 		ctx = context.WithValue(ctx, appctx.JwtContextKey, appctx.JwtTokenInfo{
 			Token:   token,
 			Expires: expires,
+			Context: aud,
 		})
 		ctx = context.WithValue(ctx, appctx.AppVersionKey, "v1.0.2")
 
@@ -7,8 +7,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/not-empty/grit-microframework-go/app/helper"
-
 	appctx "github.com/not-empty/grit-microframework-go/app/context"
 )
 
@@ -61,12 +59,6 @@ func ResponseMiddleware(next http.Handler) http.Handler {
 		h.Set("X-Request-ID", requestID)
 		h.Set("X-Profile", formatProfile(elapsed))
 
-		if rr.status != http.StatusNoContent {
-			if cursor, err := helper.BuildPageCursor(rr.body.Bytes(), r.URL.Query()); err == nil && cursor != "" {
-				h.Set("X-Page-Cursor", cursor)
-			}
-		}
-
 		if rr.status == http.StatusNoContent {
 			w.WriteHeader(http.StatusNoContent)
 			return
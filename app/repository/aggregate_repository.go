@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+// Aggregatable narrows the columns /aggregate may group by and compute over.
+// Models without it expose every schema column.
+type Aggregatable interface {
+	AggregateColumns() []string
+}
+
+func AggregateColumns(m BaseModel) []string {
+	if a, ok := m.(Aggregatable); ok {
+		return a.AggregateColumns()
+	}
+	return helper.MapKeys(m.Schema())
+}
+
+func (r *Repository[T]) Aggregate(ctx context.Context, aggs []helper.Aggregate, groupBy []string, filters []helper.Filter, limit int) ([]map[string]any, error) {
+	m := r.New()
+	return aggregateRecords(ctx, r.reader(ctx), m.Schema(), m.TableName(), aggs, groupBy, filters, limit, softDeletePolicy(m))
+}
+
+func aggregateRecords(
+	ctx context.Context,
+	db DBTX,
+	schema map[string]string,
+	table string,
+	aggs []helper.Aggregate,
+	groupBy []string,
+	filters []helper.Filter,
+	limit int,
+	sd *SoftDeletePolicy,
+) ([]map[string]any, error) {
+	d := helper.CurrentDialect()
+
+	resultSchema := make(map[string]string, len(groupBy)+len(aggs))
+	selected := helper.QuoteIdentifiers(d, groupBy)
+	for _, col := range groupBy {
+		resultSchema[col] = schema[col]
+	}
+	for _, a := range aggs {
+		selected = append(selected, a.Expression(d)+" AS "+d.QuoteIdentifier(a.Alias()))
+		resultSchema[a.Alias()] = a.SchemaType(schema)
+	}
+
+	var where []string
+	filterClause, args := helper.BuildWhereClause(filters)
+	if filterClause != "" {
+		where = append(where, strings.TrimPrefix(filterClause, "WHERE "))
+	}
+	if alive, aliveArgs := sd.filter(d, false); alive != "" {
+		where = append(where, alive)
+		args = append(args, aliveArgs...)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selected, ", "), d.QuoteIdentifier(table))
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if len(groupBy) > 0 {
+		grouped := strings.Join(helper.QuoteIdentifiers(d, groupBy), ", ")
+		query += fmt.Sprintf(" GROUP BY %s ORDER BY %s %s", grouped, grouped, d.Limit("?", ""))
+		args = append(args, limit)
+	}
+
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return helper.SchemaScanRows(helper.NewRowsAdapter(rows), resultSchema)
+}
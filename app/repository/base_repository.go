@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/not-empty/grit-microframework-go/app/helper"
@@ -19,6 +21,21 @@ type Updatable interface {
 	SetUpdatedAt(time.Time)
 }
 
+type Versionable interface {
+	VersionColumn() string
+}
+
+type AutoIncrementable interface {
+	SetInsertID(id int64)
+}
+
+type CompositeKey interface {
+	PrimaryKeys() []string
+	PrimaryKeyValues() []interface{}
+}
+
+var ErrPreconditionFailed = errors.New("record was modified by another request")
+
 type Scanner interface {
 	Scan(dest ...interface{}) error
 	Columns() ([]string, error)
@@ -36,22 +53,45 @@ type BaseModel interface {
 
 type RepositoryInterface[T BaseModel] interface {
 	New() T
-	Add(m T) error
-	Bulk(ids []string, limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string) ([]map[string]any, error)
-	BulkAdd(models []T) error
-	DeadDetail(id interface{}, fields []string) (map[string]any, error)
-	DeadList(limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error)
-	Delete(m T) error
-	Detail(id interface{}, fields []string) (map[string]any, error)
-	Edit(table, pk string, pkVal interface{}, cols []string, vals []interface{}) error
-	List(limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error)
-	ListOne(orderBy, order string, fields []string, filters []helper.Filter) (map[string]any, error)
-	Raw(query string, params map[string]any) ([]map[string]any, error)
-	Undelete(m T) error
+	Add(ctx context.Context, m T) error
+	Aggregate(ctx context.Context, aggs []helper.Aggregate, groupBy []string, filters []helper.Filter, limit int) ([]map[string]any, error)
+	Bulk(ctx context.Context, ids []string, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string) ([]map[string]any, error)
+	BulkAdd(ctx context.Context, models []T) error
+	BulkCount(ctx context.Context, ids []string) (int64, error)
+	BulkDelete(ctx context.Context, ids []interface{}) ([]BulkResult, error)
+	BulkEdit(ctx context.Context, ids []interface{}, cols []string, vals []interface{}) ([]BulkResult, error)
+	BulkEditWhere(ctx context.Context, filters []helper.Filter, cols []string, vals []interface{}) (int64, error)
+	BulkPageCursors(list []map[string]any, ids []string, limit int, pageCursor *helper.PageCursor, orderBy, order string) (next, prev string)
+	BulkUndelete(ctx context.Context, ids []interface{}) ([]BulkResult, error)
+	Count(ctx context.Context, filters []helper.Filter) (int64, error)
+	DeadCount(ctx context.Context, filters []helper.Filter) (int64, error)
+	DeadDetail(ctx context.Context, id interface{}, fields []string) (map[string]any, error)
+	DeadList(ctx context.Context, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error)
+	Delete(ctx context.Context, m T) error
+	DeleteIfMatch(ctx context.Context, m T, etag string) error
+	Detail(ctx context.Context, id interface{}, fields []string) (map[string]any, error)
+	Edit(ctx context.Context, table, pk string, pkVal interface{}, cols []string, vals []interface{}) error
+	EditIfMatch(ctx context.Context, table, pk string, pkVal interface{}, cols []string, vals []interface{}, etag string) error
+	Include(ctx context.Context, records []map[string]any, names []string, fields map[string][]string) error
+	List(ctx context.Context, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error)
+	ListOne(ctx context.Context, orderBy, order string, fields []string, filters []helper.Filter) (map[string]any, error)
+	PageCursors(list []map[string]any, limit int, pageCursor *helper.PageCursor, orderBy, order string, filters []helper.Filter) (next, prev string)
+	Raw(ctx context.Context, query string, params map[string]any) ([]map[string]any, error)
+	Purge(ctx context.Context, m T) error
+	Undelete(ctx context.Context, m T) error
+	UndeleteIfMatch(ctx context.Context, m T, etag string) error
+	Upsert(ctx context.Context, m T) (bool, error)
+	BulkUpsert(ctx context.Context, models []T) ([]bool, error)
+}
+
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 }
 
 type Repository[T BaseModel] struct {
 	DB      *sql.DB
+	tx      *sql.Tx
 	newFunc func() T
 }
 
@@ -66,65 +106,192 @@ func (r *Repository[T]) New() T {
 	return r.newFunc()
 }
 
-func (r *Repository[T]) Add(m T) error {
-	return addRecord(r.DB, m)
+func (r *Repository[T]) conn() DBTX {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.DB
+}
+
+func (r *Repository[T]) Add(ctx context.Context, m T) error {
+	return r.wrote(ctx, addRecord(ctx, r.conn(), m))
 }
 
-func (r *Repository[T]) BulkAdd(m []T) error {
+func (r *Repository[T]) BulkAdd(ctx context.Context, m []T) error {
 	baseModels := make([]BaseModel, len(m))
 	for i, model := range m {
 		baseModels[i] = model
 	}
-	return bulkAddRecords(r.DB, baseModels)
+	return r.wrote(ctx, bulkAddRecords(ctx, r.conn(), baseModels))
+}
+
+func (r *Repository[T]) Bulk(ctx context.Context, ids []string, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string) ([]map[string]any, error) {
+	m := r.New()
+	return bulkRecords(ctx, r.reader(ctx), m.Schema(), m.TableName(), PrimaryKeys(m), fields, ids, limit, offset, pageCursor, orderBy, order, softDeletePolicy(m))
 }
 
-func (r *Repository[T]) Bulk(ids []string, limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string) ([]map[string]any, error) {
+func (r *Repository[T]) DeadDetail(ctx context.Context, id interface{}, fields []string) (map[string]any, error) {
 	m := r.New()
-	return bulkRecords(r.DB, m.Schema(), m.TableName(), m.PrimaryKey(), fields, ids, limit, pageCursor, orderBy, order)
+	return getRecord(ctx, r.reader(ctx), keyValues(id), m.Schema(), m.TableName(), PrimaryKeys(m), fields, softDeletePolicy(m), true, false)
 }
 
-func (r *Repository[T]) DeadDetail(id interface{}, fields []string) (map[string]any, error) {
+func (r *Repository[T]) DeadList(ctx context.Context, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
 	m := r.New()
-	return getRecord(r.DB, id, m.Schema(), m.TableName(), m.PrimaryKey(), fields, true)
+	return listRecords(ctx, r.reader(ctx), m.Schema(), m.TableName(), PrimaryKeys(m), fields, limit, offset, pageCursor, orderBy, order, filters, softDeletePolicy(m), true)
+}
+
+func (r *Repository[T]) Delete(ctx context.Context, m T) error {
+	n, err := deleteRecord(ctx, r.conn(), m.TableName(), PrimaryKeys(m), PrimaryKeyValues(m), counterColumn(m), softDeletePolicy(m))
+	return r.wrote(ctx, noRows(ctx, r.conn(), m, PrimaryKeyValues(m), n, err, true, ErrAlreadyDeleted))
 }
 
-func (r *Repository[T]) DeadList(limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
+func (r *Repository[T]) DeleteIfMatch(ctx context.Context, m T, etag string) error {
+	err := r.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := checkETag(ctx, tx, m, PrimaryKeyValues(m), false, etag); err != nil {
+			return err
+		}
+		n, err := deleteRecord(ctx, tx, m.TableName(), PrimaryKeys(m), PrimaryKeyValues(m), counterColumn(m), softDeletePolicy(m))
+		return noRows(ctx, tx, m, PrimaryKeyValues(m), n, err, true, ErrAlreadyDeleted)
+	})
+	return r.wrote(ctx, err)
+}
+
+func (r *Repository[T]) Detail(ctx context.Context, id interface{}, fields []string) (map[string]any, error) {
 	m := r.New()
-	return listRecords(r.DB, m.Schema(), m.TableName(), fields, limit, pageCursor, orderBy, order, filters, true)
+	return getRecord(ctx, r.reader(ctx), keyValues(id), m.Schema(), m.TableName(), PrimaryKeys(m), fields, softDeletePolicy(m), false, false)
 }
 
-func (r *Repository[T]) Delete(m T) error {
-	return deleteRecord(r.DB, m.TableName(), m.PrimaryKey(), m.PrimaryKeyValue())
+func (r *Repository[T]) DetailForUpdate(ctx context.Context, id interface{}, fields []string) (map[string]any, error) {
+	m := r.New()
+	return getRecord(ctx, r.conn(), keyValues(id), m.Schema(), m.TableName(), PrimaryKeys(m), fields, softDeletePolicy(m), false, true)
 }
 
-func (r *Repository[T]) Detail(id interface{}, fields []string) (map[string]any, error) {
+func (r *Repository[T]) Edit(ctx context.Context, table, pk string, pkVal interface{}, cols []string, vals []interface{}) error {
+	if len(cols) == 0 {
+		return nil
+	}
 	m := r.New()
-	return getRecord(r.DB, id, m.Schema(), m.TableName(), m.PrimaryKey(), fields, false)
+	pkCols, pkVals := r.editKey(pk, pkVal)
+	n, err := editRecord(ctx, r.conn(), table, pkCols, pkVals, cols, vals, counterColumn(m), softDeletePolicy(m))
+	return r.wrote(ctx, editedRows(ctx, r.conn(), m, pkVals, n, err))
 }
 
-func (r *Repository[T]) Edit(table, pk string, pkVal interface{}, cols []string, vals []interface{}) error {
-	return editRecord(r.DB, table, pk, pkVal, cols, vals)
+func (r *Repository[T]) EditIfMatch(ctx context.Context, table, pk string, pkVal interface{}, cols []string, vals []interface{}, etag string) error {
+	m := r.New()
+	pkCols, pkVals := r.editKey(pk, pkVal)
+	err := r.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := checkETag(ctx, tx, m, pkVals, false, etag); err != nil {
+			return err
+		}
+		n, err := editRecord(ctx, tx, table, pkCols, pkVals, cols, vals, counterColumn(m), softDeletePolicy(m))
+		return editedRows(ctx, tx, m, pkVals, n, err)
+	})
+	return r.wrote(ctx, err)
 }
 
-func (r *Repository[T]) List(limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
+func (r *Repository[T]) List(ctx context.Context, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
 	m := r.New()
-	return listRecords(r.DB, m.Schema(), m.TableName(), fields, limit, pageCursor, orderBy, order, filters, false)
+	return listRecords(ctx, r.reader(ctx), m.Schema(), m.TableName(), PrimaryKeys(m), fields, limit, offset, pageCursor, orderBy, order, filters, softDeletePolicy(m), false)
 }
 
-func (r *Repository[T]) ListOne(orderBy, order string, fields []string, filters []helper.Filter) (map[string]any, error) {
-	results, err := r.List(1, nil, orderBy, order, fields, filters)
+func (r *Repository[T]) ListOne(ctx context.Context, orderBy, order string, fields []string, filters []helper.Filter) (map[string]any, error) {
+	results, err := r.List(ctx, 1, 0, nil, orderBy, order, fields, filters)
 	if len(results) == 0 {
 		return make(map[string]any), err
 	}
 	return results[0], err
 }
 
-func (r *Repository[T]) Raw(query string, params map[string]any) ([]map[string]any, error) {
+func (r *Repository[T]) Raw(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
 	m := r.New()
 	sqlText, args := helper.PrepareRawQuery(query, params)
-	return rawRecords(r.DB, m.Schema(), sqlText, args...)
+	return rawRecords(ctx, r.conn(), m.Schema(), sqlText, args...)
+}
+
+func (r *Repository[T]) Undelete(ctx context.Context, m T) error {
+	n, err := undeleteRecord(ctx, r.conn(), m.TableName(), PrimaryKeys(m), PrimaryKeyValues(m), counterColumn(m), softDeletePolicy(m))
+	return r.wrote(ctx, noRows(ctx, r.conn(), m, PrimaryKeyValues(m), n, err, false, ErrNotDeleted))
+}
+
+func (r *Repository[T]) UndeleteIfMatch(ctx context.Context, m T, etag string) error {
+	err := r.RunInTx(ctx, func(tx *sql.Tx) error {
+		if err := checkETag(ctx, tx, m, PrimaryKeyValues(m), true, etag); err != nil {
+			return err
+		}
+		n, err := undeleteRecord(ctx, tx, m.TableName(), PrimaryKeys(m), PrimaryKeyValues(m), counterColumn(m), softDeletePolicy(m))
+		return noRows(ctx, tx, m, PrimaryKeyValues(m), n, err, false, ErrNotDeleted)
+	})
+	return r.wrote(ctx, err)
 }
 
-func (r *Repository[T]) Undelete(m T) error {
-	return undeleteRecord(r.DB, m.TableName(), m.PrimaryKey(), m.PrimaryKeyValue())
+func (r *Repository[T]) editKey(pk string, pkVal interface{}) ([]string, []interface{}) {
+	vals := keyValues(pkVal)
+	if len(vals) > 1 {
+		return PrimaryKeys(r.New()), vals
+	}
+	return []string{pk}, vals
+}
+
+func PrimaryKeys(m BaseModel) []string {
+	if c, ok := m.(CompositeKey); ok {
+		return c.PrimaryKeys()
+	}
+	return []string{m.PrimaryKey()}
+}
+
+func PrimaryKeyValues(m BaseModel) []interface{} {
+	if c, ok := m.(CompositeKey); ok {
+		return c.PrimaryKeyValues()
+	}
+	return []interface{}{m.PrimaryKeyValue()}
+}
+
+func keyValues(id interface{}) []interface{} {
+	if vals, ok := id.([]interface{}); ok {
+		return vals
+	}
+	return []interface{}{id}
+}
+
+func VersionColumn(m BaseModel) string {
+	if v, ok := m.(Versionable); ok {
+		return v.VersionColumn()
+	}
+	if _, ok := m.(Updatable); ok {
+		return "updated_at"
+	}
+	return ""
+}
+
+// ETagColumns lists the columns a record's ETag is computed from. A
+// Versionable column is enough on its own; updated_at only moves once per
+// second on most drivers, so in that mode the tag covers every column and two
+// writes within the same second still produce different tags.
+func ETagColumns(m BaseModel) []string {
+	if v, ok := m.(Versionable); ok {
+		return []string{v.VersionColumn()}
+	}
+	if _, ok := m.(Updatable); ok {
+		return m.Columns()
+	}
+	return nil
+}
+
+func RecordETag(m BaseModel, record map[string]any) string {
+	cols := ETagColumns(m)
+	if len(cols) == 1 {
+		return helper.ETag(record[cols[0]])
+	}
+	vals := make([]any, len(cols))
+	for i, col := range cols {
+		vals[i] = record[col]
+	}
+	return helper.ETag(vals)
+}
+
+func counterColumn(m BaseModel) string {
+	if v, ok := m.(Versionable); ok {
+		return v.VersionColumn()
+	}
+	return ""
 }
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+var ErrMissingFilter = errors.New("bulk edit requires at least one filter")
+
+// BulkResult is what a bulk action did to one key. Err is nil once the record
+// was written, even when no value changed, and otherwise holds sql.ErrNoRows,
+// ErrAlreadyDeleted or ErrNotDeleted like the single-record actions.
+type BulkResult struct {
+	Affected int64
+	Err      error
+}
+
+func (r *Repository[T]) BulkEdit(ctx context.Context, ids []interface{}, cols []string, vals []interface{}) ([]BulkResult, error) {
+	m := r.New()
+	return r.eachKey(ctx, ids, func(conn DBTX, pkVals []interface{}) (int64, error) {
+		n, err := editRecord(ctx, conn, m.TableName(), PrimaryKeys(m), pkVals, cols, vals, counterColumn(m), softDeletePolicy(m))
+		return n, editedRows(ctx, conn, m, pkVals, n, err)
+	})
+}
+
+func (r *Repository[T]) BulkEditWhere(ctx context.Context, filters []helper.Filter, cols []string, vals []interface{}) (int64, error) {
+	m := r.New()
+	n, err := editWhere(ctx, r.conn(), m.TableName(), filters, cols, vals, counterColumn(m), softDeletePolicy(m))
+	return n, r.wrote(ctx, err)
+}
+
+func (r *Repository[T]) BulkDelete(ctx context.Context, ids []interface{}) ([]BulkResult, error) {
+	m := r.New()
+	return r.eachKey(ctx, ids, func(conn DBTX, pkVals []interface{}) (int64, error) {
+		n, err := deleteRecord(ctx, conn, m.TableName(), PrimaryKeys(m), pkVals, counterColumn(m), softDeletePolicy(m))
+		return n, noRows(ctx, conn, m, pkVals, n, err, true, ErrAlreadyDeleted)
+	})
+}
+
+func (r *Repository[T]) BulkUndelete(ctx context.Context, ids []interface{}) ([]BulkResult, error) {
+	m := r.New()
+	return r.eachKey(ctx, ids, func(conn DBTX, pkVals []interface{}) (int64, error) {
+		n, err := undeleteRecord(ctx, conn, m.TableName(), PrimaryKeys(m), pkVals, counterColumn(m), softDeletePolicy(m))
+		return n, noRows(ctx, conn, m, pkVals, n, err, false, ErrNotDeleted)
+	})
+}
+
+// All keys share one transaction, so a failing statement leaves every
+// record untouched; a key that is missing or on the wrong side of its soft
+// delete only marks its own result.
+func (r *Repository[T]) eachKey(ctx context.Context, ids []interface{}, fn func(conn DBTX, pkVals []interface{}) (int64, error)) ([]BulkResult, error) {
+	results := make([]BulkResult, len(ids))
+	err := inTx(ctx, r.conn(), func(conn DBTX) error {
+		for i, id := range ids {
+			n, err := fn(conn, keyValues(id))
+			if err != nil && !keyStateError(err) {
+				return err
+			}
+			results[i] = BulkResult{Affected: n, Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, r.wrote(ctx, nil)
+}
+
+func keyStateError(err error) bool {
+	return errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrAlreadyDeleted) || errors.Is(err, ErrNotDeleted)
+}
+
+func editWhere(ctx context.Context, db DBTX, table string, filters []helper.Filter, cols []string, vals []interface{}, version string, sd *SoftDeletePolicy) (int64, error) {
+	d := helper.CurrentDialect()
+	if len(cols) == 0 {
+		return 0, nil
+	}
+
+	setParts := make([]string, len(cols))
+	for i, col := range cols {
+		setParts[i] = fmt.Sprintf("%s = ?", d.QuoteIdentifier(col))
+	}
+
+	filterClause, args := helper.BuildWhereClause(filters)
+	if filterClause == "" {
+		return 0, ErrMissingFilter
+	}
+	where := []string{strings.TrimPrefix(filterClause, "WHERE ")}
+	if condition, conditionArgs := sd.filter(d, false); condition != "" {
+		where = append(where, condition)
+		args = append(args, conditionArgs...)
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s%s WHERE %s",
+		d.QuoteIdentifier(table),
+		strings.Join(setParts, ", "),
+		versionBump(d, version),
+		strings.Join(where, " AND "),
+	)
+	return execAffected(ctx, db, helper.Rebind(d, query), append(slices.Clone(vals), args...)...)
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+// A positive offset switches List, DeadList and Bulk from keyset pagination
+// to skipping the first offset rows.
+func pageLimit(d helper.Dialect, limit, offset int) (string, []interface{}) {
+	if offset > 0 {
+		return d.Limit("?", "?"), []interface{}{limit, offset}
+	}
+	return d.Limit("?", ""), []interface{}{limit}
+}
+
+func (r *Repository[T]) Count(ctx context.Context, filters []helper.Filter) (int64, error) {
+	m := r.New()
+	return countRecords(ctx, r.reader(ctx), m.TableName(), filters, nil, nil, softDeletePolicy(m), false)
+}
+
+func (r *Repository[T]) DeadCount(ctx context.Context, filters []helper.Filter) (int64, error) {
+	m := r.New()
+	sd := softDeletePolicy(m)
+	if sd == nil {
+		return 0, ErrNotSoftDeletable
+	}
+	return countRecords(ctx, r.reader(ctx), m.TableName(), filters, nil, nil, sd, true)
+}
+
+func (r *Repository[T]) BulkCount(ctx context.Context, ids []string) (int64, error) {
+	m := r.New()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	pk := PrimaryKeys(m)
+	return countRecords(ctx, r.reader(ctx), m.TableName(), nil, pk, ids, softDeletePolicy(m), false)
+}
+
+func countRecords(
+	ctx context.Context,
+	db DBTX,
+	table string,
+	filters []helper.Filter,
+	pk []string,
+	ids []string,
+	sd *SoftDeletePolicy,
+	deleted bool,
+) (int64, error) {
+	d := helper.CurrentDialect()
+
+	var where []string
+	filterClause, args := helper.BuildWhereClause(filters)
+	if filterClause != "" {
+		where = append(where, strings.TrimPrefix(filterClause, "WHERE "))
+	}
+	if condition, conditionArgs := sd.filter(d, deleted); condition != "" {
+		where = append(where, condition)
+		args = append(args, conditionArgs...)
+	}
+	if len(ids) > 0 {
+		placeholders := make([]string, len(ids))
+		for i, id := range ids {
+			key, err := splitKey(id, len(pk))
+			if err != nil {
+				return 0, err
+			}
+			placeholders[i] = keyPlaceholders(len(pk))
+			args = append(args, key...)
+		}
+		where = append(where, fmt.Sprintf("%s IN (%s)", keyTuple(d, pk), strings.Join(placeholders, ", ")))
+	}
+
+	query := "SELECT COUNT(*) FROM " + d.QuoteIdentifier(table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int64
+	if rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			return 0, err
+		}
+	}
+	return n, rows.Err()
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+func (r *Repository[T]) PageCursors(list []map[string]any, limit int, pageCursor *helper.PageCursor, orderBy, order string, filters []helper.Filter) (next, prev string) {
+	m := r.New()
+	sort := helper.ParseSort(orderBy, order, sortableColumns(m.Schema(), filters), PrimaryKeys(m))
+	return helper.PageCursors(list, limit, pageCursor, sort, filters)
+}
+
+func (r *Repository[T]) BulkPageCursors(list []map[string]any, ids []string, limit int, pageCursor *helper.PageCursor, orderBy, order string) (next, prev string) {
+	return r.PageCursors(list, limit, pageCursor, orderBy, order, bulkShape(ids))
+}
+
+// bulkShape stands in for the filters of a bulk read, so its cursors only
+// resume the id list they were issued for.
+func bulkShape(ids []string) []helper.Filter {
+	return []helper.Filter{{Field: "ids", Operator: "in", Value: strings.Join(ids, "\n")}}
+}
+
+// resumeAt checks the cursor against the query and returns the condition
+// that continues after it, along with the order the rows must be read in: a
+// backward cursor reads against the requested order and the caller reverses
+// the page afterwards.
+func resumeAt(d helper.Dialect, pk []string, sort []helper.SortKey, filters []helper.Filter, c *helper.PageCursor) (string, []interface{}, []helper.SortKey, error) {
+	if err := c.Matches(sort, filters); err != nil {
+		return "", nil, nil, err
+	}
+
+	scan := sort
+	if c.Backward {
+		scan = reverseSort(sort)
+	}
+
+	// The sort ends in key columns, which are never NULL; the trailing ones
+	// that share a direction compare as a single tuple.
+	last := scan[len(scan)-1].Order
+	tail := len(scan)
+	for tail > 0 && slices.Contains(pk, scan[tail-1].Column) && scan[tail-1].Order == last {
+		tail--
+	}
+	cols := make([]string, 0, len(scan)-tail)
+	for _, k := range scan[tail:] {
+		cols = append(cols, k.Column)
+	}
+	cond := fmt.Sprintf("%s %s %s", keyTuple(d, cols), afterOp(last), keyPlaceholders(len(cols)))
+	args := append([]interface{}{}, c.Values[tail:]...)
+
+	for i := tail - 1; i >= 0; i-- {
+		col, colArgs := sortExpression(d, scan[i].Column, filters)
+		nullable := !slices.Contains(pk, scan[i].Column) && scan[i].Column != helper.RelevanceColumn
+		cond, args = resumeTerm(col, colArgs, afterOp(scan[i].Order), c.Values[i], nullable, cond, args)
+	}
+	return cond, args, scan, nil
+}
+
+// resumeTerm continues after value v of one sort expression, deferring to
+// inner among the rows that tie with it. NULLs sort before every value (see
+// keyOrder), so they come first going up and last going down.
+func resumeTerm(col string, colArgs []interface{}, op string, v any, nullable bool, inner string, innerArgs []interface{}) (string, []interface{}) {
+	switch {
+	case v == nil && op == ">":
+		return fmt.Sprintf("( ( %s IS NULL AND %s ) OR %s IS NOT NULL )", col, inner, col), slices.Concat(colArgs, innerArgs, colArgs)
+	case v == nil:
+		return fmt.Sprintf("( %s IS NULL AND %s )", col, inner), slices.Concat(colArgs, innerArgs)
+	}
+
+	value := []interface{}{v}
+	cond := fmt.Sprintf("( %s %s ? OR ( %s = ? AND %s )", col, op, col, inner)
+	args := slices.Concat(colArgs, value, colArgs, value, innerArgs)
+	if op == "<" && nullable {
+		cond += fmt.Sprintf(" OR %s IS NULL", col)
+		args = append(args, colArgs...)
+	}
+	return cond + " )", args
+}
+
+func afterOp(order string) string {
+	if order == "DESC" {
+		return "<"
+	}
+	return ">"
+}
+
+func reverseSort(sort []helper.SortKey) []helper.SortKey {
+	reversed := make([]helper.SortKey, len(sort))
+	for i, k := range sort {
+		k.Order = reverseOrder(k.Order)
+		reversed[i] = k
+	}
+	return reversed
+}
+
+func reverseOrder(order string) string {
+	if order == "DESC" {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+func restoreOrder(list []map[string]any, c *helper.PageCursor) {
+	if c != nil && c.Backward {
+		slices.Reverse(list)
+	}
+}
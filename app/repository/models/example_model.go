@@ -3,6 +3,7 @@ package models
 import (
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
 	"time"
 )
 
@@ -54,6 +55,27 @@ func (m *Example) PrimaryKeyValue() interface{} {
 	return m.ID
 }
 
+func (m *Example) SortableColumns() []string {
+	return []string{"id", "name", "age", "deleted_at"}
+}
+
+func (m *Example) FilterableColumns() map[string][]string {
+	return map[string][]string{
+		"id":         helper.IndexedOperators,
+		"name":       nil,
+		"age":        helper.IndexedOperators,
+		"deleted_at": helper.IndexedOperators,
+	}
+}
+
+func (m *Example) SearchableColumns() []string {
+	return []string{"name"}
+}
+
+func (m *Example) SoftDeletePolicy() repository.SoftDeletePolicy {
+	return repository.SoftDeletePolicy{Column: "deleted_at"}
+}
+
 func (m *Example) SetCreatedAt(t time.Time) {
 	m.CreatedAt = &t
 }
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+const DefaultPurgeBatch = 500
+
+type Purger interface {
+	PurgeExpired(ctx context.Context, now time.Time, batch int) (int64, error)
+}
+
+func (r *Repository[T]) Purge(ctx context.Context, m T) error {
+	sd := softDeletePolicy(m)
+	if sd == nil {
+		return ErrNotSoftDeletable
+	}
+
+	err := r.RunInTx(ctx, func(tx *sql.Tx) error {
+		n, err := purgeRecords(ctx, tx, m, sd, [][]interface{}{PrimaryKeyValues(m)})
+		return noRows(ctx, tx, m, PrimaryKeyValues(m), n, err, false, ErrNotDeleted)
+	})
+	return r.wrote(ctx, err)
+}
+
+// Each batch runs in its own short transaction so concurrent writers are
+// never blocked for the whole run.
+func (r *Repository[T]) PurgeExpired(ctx context.Context, now time.Time, batch int) (int64, error) {
+	m := r.New()
+	sd := softDeletePolicy(m)
+	if sd == nil || sd.PurgeAfter <= 0 || sd.StatusDeleted != nil {
+		return 0, nil
+	}
+	if batch <= 0 {
+		batch = DefaultPurgeBatch
+	}
+
+	cutoff := now.Add(-sd.PurgeAfter)
+	var total int64
+	for {
+		keys, err := expiredKeys(ctx, r.conn(), m, sd, cutoff, batch)
+		if err != nil || len(keys) == 0 {
+			return total, err
+		}
+
+		err = r.RunInTx(ctx, func(tx *sql.Tx) error {
+			n, err := purgeRecords(ctx, tx, m, sd, keys)
+			total += n
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(keys) < batch {
+			return total, nil
+		}
+	}
+}
+
+func PurgeAll(ctx context.Context, now time.Time, batch int) (map[string]int64, error) {
+	registryMu.RLock()
+	names := make([]string, 0, len(repositories))
+	purgers := make(map[string]Purger, len(repositories))
+	for name, repo := range repositories {
+		if p, ok := repo.(Purger); ok {
+			names = append(names, name)
+			purgers[name] = p
+		}
+	}
+	registryMu.RUnlock()
+	slices.Sort(names)
+
+	purged := make(map[string]int64, len(names))
+	var errs []error
+	for _, name := range names {
+		n, err := purgers[name].PurgeExpired(ctx, now, batch)
+		purged[name] = n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge %s: %w", name, err))
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+func expiredKeys(ctx context.Context, db DBTX, m BaseModel, sd *SoftDeletePolicy, cutoff time.Time, batch int) ([][]interface{}, error) {
+	d := helper.CurrentDialect()
+	pk := PrimaryKeys(m)
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s < ? ORDER BY %s %s",
+		strings.Join(helper.QuoteIdentifiers(d, pk), ", "),
+		d.QuoteIdentifier(m.TableName()),
+		d.QuoteIdentifier(sd.Column),
+		strings.Join(helper.QuoteIdentifiers(d, pk), ", "),
+		d.Limit("?", ""),
+	)
+
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), cutoff, batch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys [][]interface{}
+	for rows.Next() {
+		key := make([]interface{}, len(pk))
+		dest := make([]interface{}, len(pk))
+		for i := range key {
+			dest[i] = &key[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func purgeRecords(ctx context.Context, db DBTX, m BaseModel, sd *SoftDeletePolicy, keys [][]interface{}) (int64, error) {
+	d := helper.CurrentDialect()
+	pk := PrimaryKeys(m)
+	table := d.QuoteIdentifier(m.TableName())
+
+	placeholders := make([]string, len(keys))
+	var args []interface{}
+	for i, key := range keys {
+		placeholders[i] = keyPlaceholders(len(pk))
+		args = append(args, key...)
+	}
+	dead, deadArgs := sd.filter(d, true)
+	where := fmt.Sprintf("%s IN (%s) AND %s", keyTuple(d, pk), strings.Join(placeholders, ", "), dead)
+	args = append(args, deadArgs...)
+
+	if archive := sd.archiveTable(m.TableName()); archive != "" {
+		cols := strings.Join(helper.QuoteIdentifiers(d, m.Columns()), ", ")
+		query := fmt.Sprintf(
+			"INSERT INTO %s (%s) SELECT %s FROM %s WHERE %s",
+			d.QuoteIdentifier(archive), cols, cols, table, where,
+		)
+		if _, err := db.ExecContext(ctx, helper.Rebind(d, query), args...); err != nil {
+			return 0, err
+		}
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", table, where)
+	return execAffected(ctx, db, helper.Rebind(d, query), args...)
+}
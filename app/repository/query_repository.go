@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+// Sortable narrows the columns order_by accepts. The primary key stays
+// sortable regardless, as it is the default order.
+type Sortable interface {
+	SortableColumns() []string
+}
+
+// Filterable maps the columns filter accepts to the operators allowed on
+// each; an empty list allows every operator.
+type Filterable interface {
+	FilterableColumns() map[string][]string
+}
+
+// Searchable lists the columns the q param searches. On MySQL they must be
+// covered, in this order, by one FULLTEXT index.
+type Searchable interface {
+	SearchableColumns() []string
+}
+
+var ErrNotSearchable = errors.New("model does not support search")
+
+func SortableColumns(m BaseModel) []string {
+	s, ok := m.(Sortable)
+	if !ok {
+		return helper.MapKeys(m.Schema())
+	}
+	cols := slices.Clone(s.SortableColumns())
+	for _, key := range PrimaryKeys(m) {
+		if !slices.Contains(cols, key) {
+			cols = append(cols, key)
+		}
+	}
+	return cols
+}
+
+func FilterableColumns(m BaseModel) map[string][]string {
+	if f, ok := m.(Filterable); ok {
+		return f.FilterableColumns()
+	}
+	return helper.AllowColumns(m.Columns()).Columns
+}
+
+func SearchableColumns(m BaseModel) []string {
+	if s, ok := m.(Searchable); ok {
+		return s.SearchableColumns()
+	}
+	return nil
+}
+
+// sortableColumns adds the search score to the schema columns while a search
+// is running.
+func sortableColumns(schema map[string]string, filters []helper.Filter) []string {
+	cols := helper.MapKeys(schema)
+	if _, ok := helper.FindSearch(filters); ok {
+		cols = append(cols, helper.RelevanceColumn)
+	}
+	return cols
+}
+
+// sortExpression is what a sort key compares: its column or, for relevance,
+// the search score with the arguments it binds.
+func sortExpression(d helper.Dialect, col string, filters []helper.Filter) (string, []interface{}) {
+	if search, ok := helper.FindSearch(filters); ok && col == helper.RelevanceColumn {
+		return helper.RelevanceExpression(d, search)
+	}
+	return d.QuoteIdentifier(col), nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+type RelationKind string
+
+const (
+	RelationBelongsTo RelationKind = "belongs_to"
+	RelationHasMany   RelationKind = "has_many"
+)
+
+// DefaultMaxIncludeRows caps the rows a has-many include loads for one page
+// when the relation does not set its own MaxRows.
+const DefaultMaxIncludeRows = 1000
+
+var (
+	ErrUnknownRelation = errors.New("unknown relation")
+	ErrIncludeTooLarge = errors.New("include exceeds the related row limit")
+)
+
+// Relation points at another registered domain. LocalKey is the column read
+// from this model's records and ForeignKey the column matched on the related
+// domain; an empty key stands for the primary key of its side. MaxRows caps
+// a has-many include for the whole page.
+type Relation struct {
+	Name       string
+	Kind       RelationKind
+	Domain     string
+	LocalKey   string
+	ForeignKey string
+	MaxRows    int
+}
+
+type Relational interface {
+	Relations() []Relation
+}
+
+func BelongsTo(name, domain, localKey string) Relation {
+	return Relation{Name: name, Kind: RelationBelongsTo, Domain: domain, LocalKey: localKey}
+}
+
+func HasMany(name, domain, foreignKey string) Relation {
+	return Relation{Name: name, Kind: RelationHasMany, Domain: domain, ForeignKey: foreignKey}
+}
+
+func (rel Relation) maxRows() int {
+	if rel.Kind != RelationHasMany {
+		return 0
+	}
+	if rel.MaxRows <= 0 {
+		return DefaultMaxIncludeRows
+	}
+	return rel.MaxRows
+}
+
+func Relations(m BaseModel) []Relation {
+	if rel, ok := m.(Relational); ok {
+		return rel.Relations()
+	}
+	return nil
+}
+
+// FindRelations resolves include names against the model's relations and
+// fills in the local keys, so callers know which columns the records need.
+func FindRelations(m BaseModel, names []string) ([]Relation, error) {
+	declared := Relations(m)
+	found := make([]Relation, 0, len(names))
+	for _, name := range names {
+		i := -1
+		for j, rel := range declared {
+			if rel.Name == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRelation, name)
+		}
+		rel := declared[i]
+		if rel.LocalKey == "" {
+			rel.LocalKey = m.PrimaryKey()
+		}
+		found = append(found, rel)
+	}
+	return found, nil
+}
+
+type relatedSource interface {
+	relatedRecords(ctx context.Context, column string, vals []interface{}, fields []string, limit int) (string, []map[string]any, error)
+}
+
+// Include loads every named relation for the records with one query per
+// relation and stores the result under the relation name: a record (or nil)
+// for belongs-to and a list for has-many. fields narrows the related columns
+// per relation name. A has-many relation with more rows than its cap fails
+// with ErrIncludeTooLarge rather than handing back partial lists.
+func (r *Repository[T]) Include(ctx context.Context, records []map[string]any, names []string, fields map[string][]string) error {
+	relations, err := FindRelations(r.New(), names)
+	if err != nil {
+		return err
+	}
+
+	for _, rel := range relations {
+		registryMu.RLock()
+		found, ok := repositories[rel.Domain]
+		registryMu.RUnlock()
+		src, isSource := found.(relatedSource)
+		if !ok || !isSource {
+			return fmt.Errorf("relation %s: repository %q is not registered", rel.Name, rel.Domain)
+		}
+
+		var vals []interface{}
+		seen := make(map[string]bool)
+		for _, record := range records {
+			v := record[rel.LocalKey]
+			if v == nil || seen[helper.KeyString(v)] {
+				continue
+			}
+			seen[helper.KeyString(v)] = true
+			vals = append(vals, v)
+		}
+
+		limit := rel.maxRows()
+		column, related, err := src.relatedRecords(ctx, rel.ForeignKey, vals, fields[rel.Name], limit)
+		if err != nil {
+			return fmt.Errorf("relation %s: %w", rel.Name, err)
+		}
+		if limit > 0 && len(related) > limit {
+			return fmt.Errorf("%w: %s has more than %d rows", ErrIncludeTooLarge, rel.Name, limit)
+		}
+
+		grouped := make(map[string][]map[string]any, len(related))
+		for _, rec := range related {
+			k := helper.KeyString(rec[column])
+			grouped[k] = append(grouped[k], rec)
+		}
+
+		for _, record := range records {
+			var matches []map[string]any
+			if v := record[rel.LocalKey]; v != nil {
+				matches = grouped[helper.KeyString(v)]
+			}
+			if rel.Kind == RelationHasMany {
+				if matches == nil {
+					matches = []map[string]any{}
+				}
+				record[rel.Name] = matches
+				continue
+			}
+			if len(matches) > 0 {
+				record[rel.Name] = matches[0]
+			} else {
+				record[rel.Name] = nil
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Repository[T]) relatedRecords(ctx context.Context, column string, vals []interface{}, fields []string, limit int) (string, []map[string]any, error) {
+	m := r.New()
+	if column == "" {
+		column = m.PrimaryKey()
+	}
+	if len(vals) == 0 {
+		return column, nil, nil
+	}
+
+	pk := PrimaryKeys(m)
+	fields = helper.ParseFieldsParam(strings.Join(fields, ","), m.Columns())
+	fields = helper.EnsureKeyFields(fields, pk, column)
+
+	list, err := relatedRecords(ctx, r.reader(ctx), m.Schema(), m.TableName(), pk, column, vals, fields, softDeletePolicy(m), limit)
+	return column, list, err
+}
+
+// A positive limit reads one row past it, so the caller can tell a full
+// result from a truncated one.
+func relatedRecords(ctx context.Context, db DBTX, schema map[string]string, table string, pk []string, column string, vals []interface{}, fields []string, sd *SoftDeletePolicy, limit int) ([]map[string]any, error) {
+	d := helper.CurrentDialect()
+	selected := helper.QuoteIdentifiers(d, helper.FilterFields(fields, helper.MapKeys(schema)))
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(vals)), ", ")
+	where := []string{fmt.Sprintf("%s IN (%s)", d.QuoteIdentifier(column), placeholders)}
+	args := append([]interface{}{}, vals...)
+	if alive, aliveArgs := sd.filter(d, false); alive != "" {
+		where = append(where, alive)
+		args = append(args, aliveArgs...)
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY %s",
+		strings.Join(selected, ", "),
+		d.QuoteIdentifier(table),
+		strings.Join(where, " AND "),
+		strings.Join(helper.QuoteIdentifiers(d, pk), ", "),
+	)
+	if limit > 0 {
+		query += " " + d.Limit("?", "")
+		args = append(args, limit+1)
+	}
+
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []map[string]any
+	for rows.Next() {
+		row, err := ScanFunc(rows, schema)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, row)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	appctx "github.com/not-empty/grit-microframework-go/app/context"
+)
+
+type primaryContextKey struct{}
+
+var (
+	replicaMu      sync.RWMutex
+	replicaDBs     []*sql.DB
+	replicaNext    atomic.Uint64
+	readYourWrites time.Duration
+	recentWrites   sync.Map
+	stopSweeper    chan struct{}
+)
+
+func SetReadReplicas(dbs ...*sql.DB) {
+	replicaMu.Lock()
+	defer replicaMu.Unlock()
+	replicaDBs = dbs
+	replicaNext.Store(0)
+}
+
+func SetReadYourWrites(window time.Duration) {
+	replicaMu.Lock()
+	defer replicaMu.Unlock()
+	readYourWrites = window
+	recentWrites.Clear()
+
+	if stopSweeper != nil {
+		close(stopSweeper)
+		stopSweeper = nil
+	}
+	if window > 0 {
+		stopSweeper = make(chan struct{})
+		go sweepRecentWrites(window, stopSweeper)
+	}
+}
+
+// sweepRecentWrites evicts, once per window, the tokens that expired without
+// being read again; the ones that are read are evicted by usePrimary.
+func sweepRecentWrites(window time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			recentWrites.Range(func(key, until any) bool {
+				if until.(time.Time).Before(now) {
+					recentWrites.CompareAndDelete(key, until)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func (r *Repository[T]) reader(ctx context.Context) DBTX {
+	if r.tx != nil {
+		return r.tx
+	}
+	if usePrimary(ctx) {
+		return r.DB
+	}
+
+	replicaMu.RLock()
+	defer replicaMu.RUnlock()
+	if len(replicaDBs) == 0 {
+		return r.DB
+	}
+	return replicaDBs[(replicaNext.Add(1)-1)%uint64(len(replicaDBs))]
+}
+
+func (r *Repository[T]) wrote(ctx context.Context, err error) error {
+	if err != nil {
+		return err
+	}
+
+	replicaMu.RLock()
+	window := readYourWrites
+	replicaMu.RUnlock()
+
+	token := requestToken(ctx)
+	if window <= 0 || token == "" {
+		return nil
+	}
+
+	recentWrites.Store(token, time.Now().Add(window))
+	return nil
+}
+
+func usePrimary(ctx context.Context) bool {
+	if primary, _ := ctx.Value(primaryContextKey{}).(bool); primary {
+		return true
+	}
+
+	token := requestToken(ctx)
+	if token == "" {
+		return false
+	}
+	until, ok := recentWrites.Load(token)
+	if !ok {
+		return false
+	}
+	if time.Now().Before(until.(time.Time)) {
+		return true
+	}
+	recentWrites.CompareAndDelete(token, until)
+	return false
+}
+
+func requestToken(ctx context.Context) string {
+	info, _ := ctx.Value(appctx.JwtContextKey).(appctx.JwtTokenInfo)
+	return info.Token
+}
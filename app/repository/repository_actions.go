@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/not-empty/grit-microframework-go/app/helper"
@@ -10,12 +14,19 @@ import (
 
 var ScanFunc = helper.GenericScanToMap
 
-func addRecord(db *sql.DB, m BaseModel) error {
+func addRecord(ctx context.Context, db DBTX, m BaseModel) error {
+	d := helper.CurrentDialect()
 	allCols := m.Columns()
 	allVals := m.Values()
 
 	defaultCols := m.HasDefaultValue()
 
+	auto, generated := m.(AutoIncrementable)
+	generated = generated && helper.IsEmptyValue(m.PrimaryKeyValue())
+	if generated && !slices.Contains(defaultCols, m.PrimaryKey()) {
+		defaultCols = append(slices.Clone(defaultCols), m.PrimaryKey())
+	}
+
 	finalCols, finalVals := helper.FilterOutDefaulted(allCols, allVals, defaultCols)
 
 	placeholders := make([]string, len(finalCols))
@@ -25,83 +36,121 @@ func addRecord(db *sql.DB, m BaseModel) error {
 
 	query := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES (%s)",
-		m.TableName(),
-		strings.Join(helper.EscapeMysqlFields(finalCols), ", "),
+		d.QuoteIdentifier(m.TableName()),
+		strings.Join(helper.QuoteIdentifiers(d, finalCols), ", "),
 		strings.Join(placeholders, ", "),
 	)
 
-	_, err := db.Exec(query, finalVals...)
-	return err
+	if !generated {
+		_, err := db.ExecContext(ctx, helper.Rebind(d, query), finalVals...)
+		return err
+	}
+
+	id, err := insertReturningID(ctx, d, db, query, m.PrimaryKey(), finalVals)
+	if err != nil {
+		return err
+	}
+	auto.SetInsertID(id)
+	return nil
+}
+
+// lib/pq does not implement LastInsertId, so Postgres reads the key back
+// through RETURNING instead.
+func insertReturningID(ctx context.Context, d helper.Dialect, db DBTX, query, pk string, args []interface{}) (int64, error) {
+	if d.Name() != helper.DriverPostgres {
+		res, err := db.ExecContext(ctx, helper.Rebind(d, query), args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query+" RETURNING "+d.QuoteIdentifier(pk)), args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var id int64
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, sql.ErrNoRows
+	}
+	if err := rows.Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, rows.Err()
 }
 
 func bulkRecords(
-	db *sql.DB,
+	ctx context.Context,
+	db DBTX,
 	schema map[string]string,
 	table string,
-	pk string,
+	pk []string,
 	fields []string,
 	ids []string,
-	limit int,
+	limit, offset int,
 	pageCursor *helper.PageCursor,
 	orderBy, order string,
+	sd *SoftDeletePolicy,
 ) ([]map[string]any, error) {
+	d := helper.CurrentDialect()
 	if len(ids) == 0 {
 		return nil, nil
 	}
 
-	selected := helper.EscapeMysqlFields(helper.FilterFields(fields, helper.MapKeys(schema)))
-	orderByEsc := helper.EscapeMysqlField(helper.ValidateOrderBy(orderBy, helper.MapKeys(schema)))
-	order = helper.ValidateOrder(order)
+	selected := helper.QuoteIdentifiers(d, helper.FilterFields(fields, helper.MapKeys(schema)))
+	sort := helper.ParseSort(orderBy, order, helper.MapKeys(schema), pk)
+	pkEsc := keyTuple(d, pk)
 
-	where := []string{"`deleted_at` IS NULL"}
+	var where []string
+	args := []interface{}{}
+	if alive, aliveArgs := sd.filter(d, false); alive != "" {
+		where = append(where, alive)
+		args = append(args, aliveArgs...)
+	}
 
+	scan := sort
 	if pageCursor != nil {
-		op := ">"
-		if order == "DESC" {
-			op = "<"
+		cond, condArgs, resumed, err := resumeAt(d, pk, sort, bulkShape(ids), pageCursor)
+		if err != nil {
+			return nil, err
 		}
-		where = append(where, fmt.Sprintf(
-			"(%s %s ? OR (%s = ? AND `%s` %s ?))",
-			orderByEsc, op,
-			orderByEsc, pk, op,
-		))
+		where = append(where, cond)
+		args = append(args, condArgs...)
+		scan = resumed
 	}
 
 	placeholders := make([]string, len(ids))
-	for i := range ids {
-		placeholders[i] = "?"
+	for i, id := range ids {
+		key, err := splitKey(id, len(pk))
+		if err != nil {
+			return nil, err
+		}
+		placeholders[i] = keyPlaceholders(len(pk))
+		args = append(args, key...)
 	}
 	where = append(where,
-		fmt.Sprintf("`%s` IN (%s)", pk, strings.Join(placeholders, ", ")),
+		fmt.Sprintf("%s IN (%s)", pkEsc, strings.Join(placeholders, ", ")),
 	)
+	limitSQL, limitArgs := pageLimit(d, limit, offset)
+	args = append(args, limitArgs...)
 
-	args := []interface{}{}
-	if pageCursor != nil {
-		args = append(args,
-			pageCursor.LastValue,
-			pageCursor.LastValue,
-			pageCursor.LastID,
-		)
-	}
-	for _, id := range ids {
-		args = append(args, id)
-	}
-	args = append(args, limit)
-
-	orderExpr := fmt.Sprintf("%s %s", orderByEsc, order)
-	if orderByEsc != "`id`" {
-		orderExpr = fmt.Sprintf("%s %s, `id` %s", orderByEsc, order, order)
-	}
+	orderExpr := keyOrder(d, scan, pk)
 
 	query := fmt.Sprintf(
-		"SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT ?",
+		"SELECT %s FROM %s WHERE %s ORDER BY %s %s",
 		strings.Join(selected, ", "),
-		table,
+		d.QuoteIdentifier(table),
 		strings.Join(where, " AND "),
 		orderExpr,
+		limitSQL,
 	)
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -115,15 +164,32 @@ func bulkRecords(
 		}
 		list = append(list, row)
 	}
+	restoreOrder(list, pageCursor)
 	return list, nil
 }
 
-func bulkAddRecords(db *sql.DB, m []BaseModel) error {
+func bulkAddRecords(ctx context.Context, db DBTX, m []BaseModel) error {
+	d := helper.CurrentDialect()
 	first := m[0]
 	table := first.TableName()
 	allCols := first.Columns()
 	defaultCols := first.HasDefaultValue()
 
+	if _, ok := first.(AutoIncrementable); ok {
+		return inTx(ctx, db, func(conn DBTX) error {
+			for _, model := range m {
+				if err := addRecord(ctx, conn, model); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	if !d.SupportsDefaultKeyword() {
+		return bulkAddRecordsByShape(ctx, d, db, table, m)
+	}
+
 	var (
 		rowsSQL []string
 		args    []interface{}
@@ -138,63 +204,142 @@ func bulkAddRecords(db *sql.DB, m []BaseModel) error {
 
 	query := fmt.Sprintf(
 		"INSERT INTO %s (%s) VALUES %s",
-		table,
-		strings.Join(helper.EscapeMysqlFields(allCols), ", "),
+		d.QuoteIdentifier(table),
+		strings.Join(helper.QuoteIdentifiers(d, allCols), ", "),
 		strings.Join(rowsSQL, ", "),
 	)
 
-	_, err := db.Exec(query, args...)
+	_, err := db.ExecContext(ctx, helper.Rebind(d, query), args...)
 	return err
 }
 
-func deleteRecord(db *sql.DB, table, pk string, pkVal interface{}) error {
+func bulkAddRecordsByShape(ctx context.Context, d helper.Dialect, db DBTX, table string, m []BaseModel) error {
+	type shape struct {
+		cols []string
+		rows []string
+		args []interface{}
+	}
+
+	var keys []string
+	shapes := make(map[string]*shape)
+	for _, model := range m {
+		cols, vals := helper.FilterOutDefaulted(model.Columns(), model.Values(), model.HasDefaultValue())
+		key := strings.Join(cols, ",")
+		s, ok := shapes[key]
+		if !ok {
+			s = &shape{cols: cols}
+			shapes[key] = s
+			keys = append(keys, key)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")
+		s.rows = append(s.rows, "("+placeholders+")")
+		s.args = append(s.args, vals...)
+	}
+
+	queries := make([]string, len(keys))
+	for i, key := range keys {
+		s := shapes[key]
+		queries[i] = helper.Rebind(d, fmt.Sprintf(
+			"INSERT INTO %s (%s) VALUES %s",
+			d.QuoteIdentifier(table),
+			strings.Join(helper.QuoteIdentifiers(d, s.cols), ", "),
+			strings.Join(s.rows, ", "),
+		))
+	}
+
+	if len(queries) == 1 {
+		_, err := db.ExecContext(ctx, queries[0], shapes[keys[0]].args...)
+		return err
+	}
+
+	return inTx(ctx, db, func(conn DBTX) error {
+		for i, query := range queries {
+			if _, err := conn.ExecContext(ctx, query, shapes[keys[i]].args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func inTx(ctx context.Context, db DBTX, fn func(conn DBTX) error) error {
+	if sqlDB, ok := db.(*sql.DB); ok {
+		return RunInTx(ctx, sqlDB, func(tx *sql.Tx) error {
+			return fn(tx)
+		})
+	}
+	return fn(db)
+}
+
+func deleteRecord(ctx context.Context, db DBTX, table string, pk []string, pkVals []interface{}, version string, sd *SoftDeletePolicy) (int64, error) {
+	d := helper.CurrentDialect()
+	if sd == nil {
+		query := fmt.Sprintf(
+			"DELETE FROM %s WHERE %s",
+			d.QuoteIdentifier(table),
+			keyCondition(d, pk),
+		)
+		return execAffected(ctx, db, helper.Rebind(d, query), pkVals...)
+	}
+
+	set, args := sd.deleteSet(ctx, d)
+	alive, aliveArgs := sd.filter(d, false)
 	query := fmt.Sprintf(
-		"UPDATE %s SET `deleted_at` = NOW() WHERE `%s` = ? AND `deleted_at` IS NULL",
-		table,
-		pk,
+		"UPDATE %s SET %s%s WHERE %s AND %s",
+		d.QuoteIdentifier(table),
+		set,
+		versionBump(d, version),
+		keyCondition(d, pk),
+		alive,
 	)
-	_, err := db.Exec(query, pkVal)
-	return err
+	args = append(append(args, pkVals...), aliveArgs...)
+	return execAffected(ctx, db, helper.Rebind(d, query), args...)
 }
 
-func editRecord(db *sql.DB, table, pk string, pkVal interface{}, cols []string, vals []interface{}) error {
+func editRecord(ctx context.Context, db DBTX, table string, pk []string, pkVals []interface{}, cols []string, vals []interface{}, version string, sd *SoftDeletePolicy) (int64, error) {
+	d := helper.CurrentDialect()
 	if len(cols) == 0 {
-		return nil
+		return 0, nil
 	}
 
 	setParts := make([]string, len(cols))
 	for i, col := range cols {
-		setParts[i] = fmt.Sprintf("`%s` = ?", col)
+		setParts[i] = fmt.Sprintf("%s = ?", d.QuoteIdentifier(col))
 	}
 
+	where, whereArgs := keyWhere(d, pk, pkVals, sd, false)
 	query := fmt.Sprintf(
-		"UPDATE %s SET %s WHERE `%s` = ? AND `deleted_at` IS NULL",
-		table,
+		"UPDATE %s SET %s%s WHERE %s",
+		d.QuoteIdentifier(table),
 		strings.Join(setParts, ", "),
-		pk,
+		versionBump(d, version),
+		where,
 	)
 
-	vals = append(vals, pkVal)
-	_, err := db.Exec(query, vals...)
-	return err
+	vals = append(slices.Clone(vals), whereArgs...)
+	return execAffected(ctx, db, helper.Rebind(d, query), vals...)
 }
 
-func getRecord(db *sql.DB, id interface{}, schema map[string]string, table string, pk string, fields []string, deleted bool) (map[string]any, error) {
-	selected := helper.EscapeMysqlFields(helper.FilterFields(fields, helper.MapKeys(schema)))
-	condition := "`deleted_at` IS NULL"
-	if deleted {
-		condition = "`deleted_at` IS NOT NULL"
+func getRecord(ctx context.Context, db DBTX, pkVals []interface{}, schema map[string]string, table string, pk []string, fields []string, sd *SoftDeletePolicy, deleted, forUpdate bool) (map[string]any, error) {
+	d := helper.CurrentDialect()
+	if deleted && sd == nil {
+		return nil, ErrNotSoftDeletable
 	}
+	selected := helper.QuoteIdentifiers(d, helper.FilterFields(fields, helper.MapKeys(schema)))
+	where, args := keyWhere(d, pk, pkVals, sd, deleted)
 
 	query := fmt.Sprintf(
-		"SELECT %s FROM %s WHERE `%s` = ? AND %s LIMIT 1",
+		"SELECT %s FROM %s WHERE %s %s",
 		strings.Join(selected, ", "),
-		table,
-		pk,
-		condition,
+		d.QuoteIdentifier(table),
+		where,
+		d.Limit("1", ""),
 	)
+	if forUpdate && d.ForUpdate() != "" {
+		query += " " + d.ForUpdate()
+	}
 
-	rows, err := db.Query(query, id)
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -207,67 +352,76 @@ func getRecord(db *sql.DB, id interface{}, schema map[string]string, table strin
 }
 
 func listRecords(
-	db *sql.DB,
+	ctx context.Context,
+	db DBTX,
 	schema map[string]string,
 	table string,
+	pk []string,
 	fields []string,
-	limit int,
+	limit, offset int,
 	pageCursor *helper.PageCursor,
 	orderBy, order string,
 	filters []helper.Filter,
+	sd *SoftDeletePolicy,
 	deleted bool,
 ) ([]map[string]any, error) {
-	selected := helper.EscapeMysqlFields(helper.FilterFields(fields, helper.MapKeys(schema)))
-	orderByEsc := helper.EscapeMysqlField(helper.ValidateOrderBy(orderBy, helper.MapKeys(schema)))
-	order = helper.ValidateOrder(order)
-
-	whereClause, args := helper.BuildWhereClause(filters)
-	if deleted {
-		if whereClause == "" {
-			whereClause = "WHERE `deleted_at` IS NOT NULL"
-		} else {
-			whereClause += " AND `deleted_at` IS NOT NULL"
-		}
-	} else {
-		if whereClause == "" {
-			whereClause = "WHERE `deleted_at` IS NULL"
-		} else {
-			whereClause += " AND `deleted_at` IS NULL"
-		}
+	d := helper.CurrentDialect()
+	if deleted && sd == nil {
+		return nil, ErrNotSoftDeletable
+	}
+	selected := helper.QuoteIdentifiers(d, helper.FilterFields(fields, helper.MapKeys(schema)))
+	sort := helper.ParseSort(orderBy, order, sortableColumns(schema, filters), pk)
+
+	// The score is selected so the page cursor can carry it.
+	var selectArgs []interface{}
+	if slices.ContainsFunc(sort, func(k helper.SortKey) bool { return k.Column == helper.RelevanceColumn }) {
+		expr, exprArgs := sortExpression(d, helper.RelevanceColumn, filters)
+		selected = append(selected, expr+" AS "+d.QuoteIdentifier(helper.RelevanceColumn))
+		selectArgs = exprArgs
+		schema = maps.Clone(schema)
+		schema[helper.RelevanceColumn] = "float64"
+	}
+
+	var where []string
+	filterClause, args := helper.BuildWhereClause(filters)
+	args = append(selectArgs, args...)
+	if filterClause != "" {
+		where = append(where, strings.TrimPrefix(filterClause, "WHERE "))
+	}
+	if condition, conditionArgs := sd.filter(d, deleted); condition != "" {
+		where = append(where, condition)
+		args = append(args, conditionArgs...)
 	}
 
+	scan := sort
 	if pageCursor != nil {
-		op := ">"
-		if order == "DESC" {
-			op = "<"
+		cond, condArgs, resumed, err := resumeAt(d, pk, sort, filters, pageCursor)
+		if err != nil {
+			return nil, err
 		}
-		whereClause += fmt.Sprintf(
-			" AND ( %s %s ? OR ( %s = ? AND `id` %s ? ) )",
-			orderByEsc, op,
-			orderByEsc,
-			op,
-		)
-		args = append(args,
-			pageCursor.LastValue,
-			pageCursor.LastValue,
-			pageCursor.LastID,
-		)
+		where = append(where, cond)
+		args = append(args, condArgs...)
+		scan = resumed
 	}
-	orderExpr := fmt.Sprintf("%s %s", orderByEsc, order)
-	if orderByEsc != "`id`" {
-		orderExpr = fmt.Sprintf("%s %s, `id` %s", orderByEsc, order, order)
+	orderExpr := keyOrder(d, scan, pk)
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE " + strings.Join(where, " AND ")
 	}
+	limitSQL, limitArgs := pageLimit(d, limit, offset)
 
 	query := fmt.Sprintf(
-		"SELECT %s FROM %s %s ORDER BY %s LIMIT ?",
+		"SELECT %s FROM %s %s ORDER BY %s %s",
 		strings.Join(selected, ", "),
-		table,
+		d.QuoteIdentifier(table),
 		whereClause,
 		orderExpr,
+		limitSQL,
 	)
-	args = append(args, limit)
+	args = append(args, limitArgs...)
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
 	if err != nil {
 		return nil, err
 	}
@@ -281,25 +435,159 @@ func listRecords(
 		}
 		list = append(list, row)
 	}
+	restoreOrder(list, pageCursor)
 	return list, nil
 }
 
-func rawRecords(db *sql.DB, _ map[string]string, sqlText string, args ...interface{}) ([]map[string]any, error) {
-	rows, err := db.Query(sqlText, args...)
+func rawRecords(ctx context.Context, db DBTX, schema map[string]string, sqlText string, args ...interface{}) ([]map[string]any, error) {
+	rows, err := db.QueryContext(ctx, sqlText, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return helper.SimpleScanRows(helper.NewRowsAdapter(rows))
+	return helper.SchemaScanRows(helper.NewRowsAdapter(rows), schema)
 }
 
-func undeleteRecord(db *sql.DB, table, pk string, pkVal interface{}) error {
+func undeleteRecord(ctx context.Context, db DBTX, table string, pk []string, pkVals []interface{}, version string, sd *SoftDeletePolicy) (int64, error) {
+	if sd == nil {
+		return 0, ErrNotSoftDeletable
+	}
+	d := helper.CurrentDialect()
+	set, args := sd.undeleteSet(d)
+	where, whereArgs := keyWhere(d, pk, pkVals, sd, true)
 	query := fmt.Sprintf(
-		"UPDATE %s SET `deleted_at` = NULL WHERE `%s` = ? AND `deleted_at` IS NOT NULL",
-		table,
-		pk,
+		"UPDATE %s SET %s%s WHERE %s",
+		d.QuoteIdentifier(table),
+		set,
+		versionBump(d, version),
+		where,
 	)
-	_, err := db.Exec(query, pkVal)
+	return execAffected(ctx, db, helper.Rebind(d, query), append(args, whereArgs...)...)
+}
+
+func execAffected(ctx context.Context, db DBTX, query string, args ...interface{}) (int64, error) {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// A zero count does not always mean the record is missing: MySQL counts
+// changed rows only, and the record may sit on the other side of its soft
+// delete, in which case stateErr is returned.
+func noRows(ctx context.Context, db DBTX, m BaseModel, pkVals []interface{}, n int64, err error, deleted bool, stateErr error) error {
+	if err != nil || n > 0 {
+		return err
+	}
+
+	pk := PrimaryKeys(m)
+	_, err = getRecord(ctx, db, pkVals, m.Schema(), m.TableName(), pk, pk, softDeletePolicy(m), deleted, false)
+	switch {
+	case err == nil:
+		return stateErr
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, ErrNotSoftDeletable):
+		return sql.ErrNoRows
+	}
 	return err
 }
+
+func editedRows(ctx context.Context, db DBTX, m BaseModel, pkVals []interface{}, n int64, err error) error {
+	err = noRows(ctx, db, m, pkVals, n, err, false, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return noRows(ctx, db, m, pkVals, 0, nil, true, ErrAlreadyDeleted)
+	}
+	return err
+}
+
+func checkETag(ctx context.Context, db DBTX, m BaseModel, pkVals []interface{}, deleted bool, etag string) error {
+	cols := ETagColumns(m)
+	if len(cols) == 0 {
+		return nil
+	}
+
+	row, err := getRecord(ctx, db, pkVals, m.Schema(), m.TableName(), PrimaryKeys(m), cols, softDeletePolicy(m), deleted, true)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPreconditionFailed
+	}
+	if err != nil {
+		return err
+	}
+	if !helper.MatchETag(etag, RecordETag(m, row)) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+func versionBump(d helper.Dialect, version string) string {
+	if version == "" {
+		return ""
+	}
+	esc := d.QuoteIdentifier(version)
+	return fmt.Sprintf(", %s = %s + 1", esc, esc)
+}
+
+func keyCondition(d helper.Dialect, pk []string) string {
+	parts := make([]string, len(pk))
+	for i, col := range pk {
+		parts[i] = d.QuoteIdentifier(col) + " = ?"
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func keyWhere(d helper.Dialect, pk []string, pkVals []interface{}, sd *SoftDeletePolicy, deleted bool) (string, []interface{}) {
+	where := keyCondition(d, pk)
+	args := slices.Clone(pkVals)
+	if condition, conditionArgs := sd.filter(d, deleted); condition != "" {
+		where += " AND " + condition
+		args = append(args, conditionArgs...)
+	}
+	return where, args
+}
+
+func keyTuple(d helper.Dialect, pk []string) string {
+	if len(pk) == 1 {
+		return d.QuoteIdentifier(pk[0])
+	}
+	return "(" + strings.Join(helper.QuoteIdentifiers(d, pk), ", ") + ")"
+}
+
+func keyPlaceholders(n int) string {
+	if n == 1 {
+		return "?"
+	}
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// keyOrder sorts NULLs before every value, which MySQL and SQLite already
+// do and Postgres has to be told.
+func keyOrder(d helper.Dialect, sort []helper.SortKey, pk []string) string {
+	parts := make([]string, len(sort))
+	for i, k := range sort {
+		parts[i] = d.QuoteIdentifier(k.Column) + " " + k.Order
+		if d.Name() == helper.DriverPostgres && !slices.Contains(pk, k.Column) {
+			if k.Order == "DESC" {
+				parts[i] += " NULLS LAST"
+			} else {
+				parts[i] += " NULLS FIRST"
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func splitKey(raw string, n int) ([]interface{}, error) {
+	if n == 1 {
+		return []interface{}{raw}, nil
+	}
+	parts := strings.Split(raw, helper.KeySeparator)
+	if len(parts) != n {
+		return nil, fmt.Errorf("key %q must have %d parts", raw, n)
+	}
+	vals := make([]interface{}, n)
+	for i, p := range parts {
+		vals[i] = p
+	}
+	return vals, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+
+	appctx "github.com/not-empty/grit-microframework-go/app/context"
+)
+
+var (
+	ErrNotSoftDeletable = errors.New("model does not support soft delete")
+	ErrAlreadyDeleted   = errors.New("record is already deleted")
+	ErrNotDeleted       = errors.New("record is not deleted")
+)
+
+type SoftDeletable interface {
+	SoftDeletePolicy() SoftDeletePolicy
+}
+
+// Column holds the deletion timestamp unless StatusDeleted is set, in which
+// case it is a status column switched between StatusActive and StatusDeleted.
+// PurgeAfter only applies to timestamp columns.
+type SoftDeletePolicy struct {
+	Column        string
+	DeletedBy     string
+	Reason        string
+	StatusActive  any
+	StatusDeleted any
+
+	PurgeAfter   time.Duration
+	Archive      bool
+	ArchiveTable string
+}
+
+type deleteReasonContextKey struct{}
+
+func WithDeleteReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, deleteReasonContextKey{}, reason)
+}
+
+func DeleteReason(ctx context.Context) string {
+	reason, _ := ctx.Value(deleteReasonContextKey{}).(string)
+	return reason
+}
+
+func IsSoftDeletable(m BaseModel) bool {
+	_, ok := m.(SoftDeletable)
+	return ok
+}
+
+func softDeletePolicy(m BaseModel) *SoftDeletePolicy {
+	s, ok := m.(SoftDeletable)
+	if !ok {
+		return nil
+	}
+	p := s.SoftDeletePolicy()
+	if p.Column == "" {
+		p.Column = "deleted_at"
+	}
+	return &p
+}
+
+func (p *SoftDeletePolicy) filter(d helper.Dialect, deleted bool) (string, []interface{}) {
+	if p == nil {
+		return "", nil
+	}
+	col := d.QuoteIdentifier(p.Column)
+	if p.StatusDeleted != nil {
+		if deleted {
+			return col + " = ?", []interface{}{p.StatusDeleted}
+		}
+		return col + " <> ?", []interface{}{p.StatusDeleted}
+	}
+	if deleted {
+		return col + " IS NOT NULL", nil
+	}
+	return col + " IS NULL", nil
+}
+
+func (p *SoftDeletePolicy) isDeleted(v any) bool {
+	if p.StatusDeleted != nil {
+		return helper.KeyString(v) == helper.KeyString(p.StatusDeleted)
+	}
+	return v != nil
+}
+
+func (p *SoftDeletePolicy) deleteSet(ctx context.Context, d helper.Dialect) (string, []interface{}) {
+	col := d.QuoteIdentifier(p.Column)
+	set, args := col+" = "+d.CurrentTimestamp(), []interface{}{}
+	if p.StatusDeleted != nil {
+		set, args = col+" = ?", []interface{}{p.StatusDeleted}
+	}
+
+	if p.DeletedBy != "" {
+		info, _ := ctx.Value(appctx.JwtContextKey).(appctx.JwtTokenInfo)
+		set += ", " + d.QuoteIdentifier(p.DeletedBy) + " = ?"
+		args = append(args, nullIfEmpty(info.Context))
+	}
+	if p.Reason != "" {
+		set += ", " + d.QuoteIdentifier(p.Reason) + " = ?"
+		args = append(args, nullIfEmpty(DeleteReason(ctx)))
+	}
+	return set, args
+}
+
+func (p *SoftDeletePolicy) undeleteSet(d helper.Dialect) (string, []interface{}) {
+	col := d.QuoteIdentifier(p.Column)
+	set, args := col+" = NULL", []interface{}{}
+	if p.StatusDeleted != nil {
+		set, args = col+" = ?", []interface{}{p.StatusActive}
+	}
+
+	for _, extra := range []string{p.DeletedBy, p.Reason} {
+		if extra != "" {
+			set += ", " + d.QuoteIdentifier(extra) + " = NULL"
+		}
+	}
+	return set, args
+}
+
+func (p *SoftDeletePolicy) archiveTable(table string) string {
+	if p.ArchiveTable != "" {
+		return p.ArchiveTable
+	}
+	if p.Archive {
+		return strings.Trim(table, "`\"") + "_archive"
+	}
+	return ""
+}
+
+func nullIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
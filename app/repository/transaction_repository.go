@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+var (
+	registryMu   sync.RWMutex
+	repositories = make(map[string]any)
+)
+
+func RegisterRepository[T BaseModel](name string, repo *Repository[T]) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	repositories[name] = repo
+}
+
+func GetRepository[T BaseModel](name string) (*Repository[T], error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	found, ok := repositories[name]
+	if !ok {
+		return nil, fmt.Errorf("repository %q is not registered", name)
+	}
+	repo, ok := found.(*Repository[T])
+	if !ok {
+		return nil, fmt.Errorf("repository %q is registered for another model", name)
+	}
+	return repo, nil
+}
+
+func TxRepository[T BaseModel](tx *sql.Tx, name string) (*Repository[T], error) {
+	repo, err := GetRepository[T](name)
+	if err != nil {
+		return nil, err
+	}
+	return repo.WithTx(tx), nil
+}
+
+func RunInTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *Repository[T]) WithTx(tx *sql.Tx) *Repository[T] {
+	return &Repository[T]{
+		DB:      r.DB,
+		tx:      tx,
+		newFunc: r.newFunc,
+	}
+}
+
+func (r *Repository[T]) RunInTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	return RunInTx(ctx, r.DB, fn)
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+)
+
+// Upsert reports whether the record was created rather than updated.
+func (r *Repository[T]) Upsert(ctx context.Context, m T) (bool, error) {
+	created, err := upsertRecords(ctx, r.conn(), []BaseModel{m})
+	if err := r.wrote(ctx, err); err != nil {
+		return false, err
+	}
+	return created[0], nil
+}
+
+// BulkUpsert reports, in step with the models, which records were created.
+func (r *Repository[T]) BulkUpsert(ctx context.Context, m []T) ([]bool, error) {
+	baseModels := make([]BaseModel, len(m))
+	for i, model := range m {
+		baseModels[i] = model
+	}
+	created, err := upsertRecords(ctx, r.conn(), baseModels)
+	return created, r.wrote(ctx, err)
+}
+
+// The keys are read and locked before writing, which tells inserts from
+// updates on every driver and refuses a key that belongs to a soft-deleted
+// record with ErrAlreadyDeleted: updating it would leave it hidden. Rows are
+// grouped by the columns they insert, so a defaulted column left empty is
+// neither inserted nor overwritten on conflict.
+func upsertRecords(ctx context.Context, db DBTX, m []BaseModel) ([]bool, error) {
+	created := make([]bool, len(m))
+	err := inTx(ctx, db, func(conn DBTX) error {
+		existing, err := existingKeys(ctx, conn, m)
+		if err != nil {
+			return err
+		}
+		for i, model := range m {
+			key := helper.JoinKey(PrimaryKeyValues(model))
+			if _, found := existing[key]; !found {
+				created[i] = true
+				existing[key] = false
+			}
+		}
+		return writeUpserts(ctx, conn, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// existingKeys maps the keys of the stored records among m to whether they
+// are soft-deleted, failing on the first deleted one.
+func existingKeys(ctx context.Context, db DBTX, m []BaseModel) (map[string]bool, error) {
+	d := helper.CurrentDialect()
+	first := m[0]
+	pk := PrimaryKeys(first)
+	sd := softDeletePolicy(first)
+
+	var (
+		placeholders []string
+		args         []interface{}
+	)
+	for _, model := range m {
+		if _, ok := model.(AutoIncrementable); ok && helper.IsEmptyValue(model.PrimaryKeyValue()) {
+			continue
+		}
+		placeholders = append(placeholders, keyPlaceholders(len(pk)))
+		args = append(args, PrimaryKeyValues(model)...)
+	}
+
+	existing := make(map[string]bool)
+	if len(placeholders) == 0 {
+		return existing, nil
+	}
+
+	fields := slices.Clone(pk)
+	if sd != nil {
+		fields = append(fields, sd.Column)
+	}
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s IN (%s)",
+		strings.Join(helper.QuoteIdentifiers(d, fields), ", "),
+		d.QuoteIdentifier(first.TableName()),
+		keyTuple(d, pk),
+		strings.Join(placeholders, ", "),
+	)
+	if d.ForUpdate() != "" {
+		query += " " + d.ForUpdate()
+	}
+
+	rows, err := db.QueryContext(ctx, helper.Rebind(d, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row, err := ScanFunc(rows, first.Schema())
+		if err != nil {
+			return nil, err
+		}
+		vals := make([]interface{}, len(pk))
+		for i, col := range pk {
+			vals[i] = row[col]
+		}
+		if sd != nil && sd.isDeleted(row[sd.Column]) {
+			return nil, ErrAlreadyDeleted
+		}
+		existing[helper.JoinKey(vals)] = false
+	}
+	return existing, rows.Err()
+}
+
+func writeUpserts(ctx context.Context, db DBTX, m []BaseModel) error {
+	d := helper.CurrentDialect()
+
+	var (
+		queries []string
+		args    [][]interface{}
+		added   []BaseModel
+	)
+	index := make(map[string]int)
+	for _, model := range m {
+		if _, ok := model.(AutoIncrementable); ok && helper.IsEmptyValue(model.PrimaryKeyValue()) {
+			added = append(added, model)
+			continue
+		}
+
+		cols, vals := helper.FilterOutDefaulted(model.Columns(), model.Values(), model.HasDefaultValue())
+		shape := strings.Join(cols, ",")
+		placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ") + ")"
+
+		i, ok := index[shape]
+		if !ok {
+			i = len(queries)
+			index[shape] = i
+			queries = append(queries, fmt.Sprintf(
+				"INSERT INTO %s (%s) VALUES %s",
+				d.QuoteIdentifier(model.TableName()),
+				strings.Join(helper.QuoteIdentifiers(d, cols), ", "),
+				placeholders,
+			))
+			args = append(args, nil)
+		} else {
+			queries[i] += ", " + placeholders
+		}
+		args[i] = append(args[i], vals...)
+	}
+
+	for shape, i := range index {
+		queries[i] = helper.Rebind(d, queries[i]+" "+upsertClause(d, m[0], strings.Split(shape, ",")))
+	}
+
+	for i, query := range queries {
+		if _, err := db.ExecContext(ctx, query, args[i]...); err != nil {
+			return err
+		}
+	}
+	for _, model := range added {
+		if err := addRecord(ctx, db, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertClause(d helper.Dialect, m BaseModel, cols []string) string {
+	pk := PrimaryKeys(m)
+	version := counterColumn(m)
+
+	skip := append(slices.Clone(pk), version)
+	if _, ok := m.(Creatable); ok {
+		skip = append(skip, "created_at")
+	}
+	if sd := softDeletePolicy(m); sd != nil {
+		skip = append(skip, sd.Column, sd.DeletedBy, sd.Reason)
+	}
+
+	var update []string
+	for _, col := range cols {
+		if !slices.Contains(skip, col) {
+			update = append(update, col)
+		}
+	}
+
+	clause := d.Upsert(pk, update)
+	if len(update) > 0 && version != "" {
+		esc := d.QuoteIdentifier(version)
+		clause += fmt.Sprintf(", %s = %s.%s + 1", esc, d.QuoteIdentifier(m.TableName()), esc)
+	}
+	return clause
+}
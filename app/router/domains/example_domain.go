@@ -14,6 +14,8 @@ func init() {
 		repo := repository.NewRepository(db, func() *models.Example {
 			return new(models.Example)
 		})
+		repository.RegisterRepository("example", repo)
+
 		baseRoutes := &route.BaseRoutes[*models.Example]{
 			Repo:   repo,
 			Prefix: "/example",
@@ -2,31 +2,62 @@ package route
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/not-empty/grit-microframework-go/app/config"
 	"github.com/not-empty/grit-microframework-go/app/controller"
+	"github.com/not-empty/grit-microframework-go/app/helper"
 	"github.com/not-empty/grit-microframework-go/app/middleware"
 	"github.com/not-empty/grit-microframework-go/app/repository"
 )
 
 type BaseRoutes[T repository.BaseModel] struct {
-	Repo   *repository.Repository[T]
-	Prefix string
-	SetPK  func(m T, id string)
+	Repo           *repository.Repository[T]
+	Prefix         string
+	SetPK          func(m T, id string)
+	SetKey         func(m T, key []interface{})
+	IDStrategy     helper.IDStrategy
+	QueryTimeout   time.Duration
+	RequireIfMatch bool
+	MaxOffset      int
+	StrictQuery    bool
+	MaxFilters     int
+	MaxInValues    int
+	MaxBulkIDs     int
 }
 
 func (br *BaseRoutes[T]) RegisterRoutes() {
 	ctrl := controller.NewBaseController(br.Repo, br.Prefix, br.SetPK)
+	ctrl.SetKey = br.SetKey
+	ctrl.IDStrategy = br.IDStrategy
+	ctrl.QueryTimeout = br.QueryTimeout
+	ctrl.RequireIfMatch = br.RequireIfMatch
+	ctrl.MaxOffset = br.MaxOffset
+	ctrl.StrictQuery = br.StrictQuery || config.AppConfig.AppStrictQuery
+	ctrl.MaxFilters = br.MaxFilters
+	ctrl.MaxInValues = br.MaxInValues
+	ctrl.MaxBulkIDs = br.MaxBulkIDs
 
 	http.Handle(br.Prefix+"/add", middleware.ClosedChain(http.HandlerFunc(ctrl.Add)))
+	http.Handle(br.Prefix+"/aggregate", middleware.ClosedChain(http.HandlerFunc(ctrl.Aggregate)))
 	http.Handle(br.Prefix+"/bulk", middleware.ClosedChain(http.HandlerFunc(ctrl.Bulk)))
 	http.Handle(br.Prefix+"/bulk_add", middleware.ClosedChain(http.HandlerFunc(ctrl.BulkAdd)))
-	http.Handle(br.Prefix+"/dead_detail/", middleware.ClosedChain(http.HandlerFunc(ctrl.DeadDetail)))
-	http.Handle(br.Prefix+"/dead_list", middleware.ClosedChain(http.HandlerFunc(ctrl.DeadList)))
+	http.Handle(br.Prefix+"/bulk_delete", middleware.ClosedChain(http.HandlerFunc(ctrl.BulkDelete)))
+	http.Handle(br.Prefix+"/bulk_edit", middleware.ClosedChain(http.HandlerFunc(ctrl.BulkEdit)))
+	http.Handle(br.Prefix+"/bulk_upsert", middleware.ClosedChain(http.HandlerFunc(ctrl.BulkUpsert)))
 	http.Handle(br.Prefix+"/delete/", middleware.ClosedChain(http.HandlerFunc(ctrl.Delete)))
 	http.Handle(br.Prefix+"/detail/", middleware.ClosedChain(http.HandlerFunc(ctrl.Detail)))
 	http.Handle(br.Prefix+"/edit/", middleware.ClosedChain(http.HandlerFunc(ctrl.Edit)))
 	http.Handle(br.Prefix+"/list", middleware.ClosedChain(http.HandlerFunc(ctrl.List)))
 	http.Handle(br.Prefix+"/list_one", middleware.ClosedChain(http.HandlerFunc(ctrl.ListOne)))
 	http.Handle(br.Prefix+"/select_raw", middleware.ClosedChain(http.HandlerFunc(ctrl.Raw)))
-	http.Handle(br.Prefix+"/undelete/", middleware.ClosedChain(http.HandlerFunc(ctrl.Undelete)))
+	http.Handle(br.Prefix+"/upsert", middleware.ClosedChain(http.HandlerFunc(ctrl.Upsert)))
+
+	if repository.IsSoftDeletable(br.Repo.New()) {
+		http.Handle(br.Prefix+"/bulk_undelete", middleware.ClosedChain(http.HandlerFunc(ctrl.BulkUndelete)))
+		http.Handle(br.Prefix+"/dead_detail/", middleware.ClosedChain(http.HandlerFunc(ctrl.DeadDetail)))
+		http.Handle(br.Prefix+"/dead_list", middleware.ClosedChain(http.HandlerFunc(ctrl.DeadList)))
+		http.Handle(br.Prefix+"/purge/", middleware.ClosedChain(http.HandlerFunc(ctrl.Purge)))
+		http.Handle(br.Prefix+"/undelete/", middleware.ClosedChain(http.HandlerFunc(ctrl.Undelete)))
+	}
 }
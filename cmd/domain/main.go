@@ -6,23 +6,32 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"text/template"
 	"unicode"
 )
 
 type DomainData struct {
-	Domain      string
-	DomainLower string
-	TableName   string
-	Fields      string
-	Columns     string
-	Values      string
-	Sanitize    string
-	Schema      string
-	HasSanitize bool
-	HasDateTime bool
-	DefaultCols string
+	Domain        string
+	DomainLower   string
+	TableName     string
+	Fields        string
+	Columns       string
+	Values        string
+	Sanitize      string
+	Schema        string
+	HasSanitize   bool
+	HasDateTime   bool
+	HasJSON       bool
+	DefaultCols   string
+	IDType        string
+	IDSchema      string
+	IntID         bool
+	AutoIncrement bool
+	Sortable      string
+	Filterable    string
+	Searchable    string
 }
 
 func Capitalize(s string) string {
@@ -59,10 +68,32 @@ func extractTableName(ddl string) string {
 	return strings.Trim(rest[:end], "`\"")
 }
 
+func parseIDColumn(ddl string) (goType, schemaType string, autoIncrement bool) {
+	goType, schemaType = "string", "string"
+	for _, raw := range strings.Split(ddl, "\n") {
+		tokens := strings.Fields(strings.TrimSpace(raw))
+		if len(tokens) < 2 || strings.Trim(tokens[0], "`\"") != "id" {
+			continue
+		}
+
+		sqlType := strings.ToLower(tokens[1])
+		upperLine := strings.ToUpper(raw)
+		if strings.Contains(sqlType, "int") || strings.Contains(sqlType, "serial") {
+			goType, schemaType = "int64", "int"
+			autoIncrement = strings.Contains(sqlType, "serial") ||
+				strings.Contains(upperLine, "AUTO_INCREMENT") ||
+				strings.Contains(upperLine, "AUTOINCREMENT") ||
+				strings.Contains(upperLine, "IDENTITY")
+		}
+		return
+	}
+	return
+}
+
 func parseExtraFields(
 	ddl string,
 ) (fields, columns, values, sanitize, schema, defaultColsList string,
-	hasSanitize, hasDateTime bool,
+	hasSanitize, hasDateTime, hasJSON bool,
 ) {
 	lines := strings.Split(ddl, "\n")
 
@@ -77,12 +108,7 @@ func parseExtraFields(
 		line := strings.TrimSpace(raw)
 		upperLine := strings.ToUpper(line)
 
-		if line == "" ||
-			strings.HasPrefix(line, ")") ||
-			strings.HasPrefix(upperLine, "PRIMARY") ||
-			strings.HasPrefix(upperLine, "KEY") ||
-			strings.HasPrefix(upperLine, "UNIQUE") ||
-			strings.HasPrefix(upperLine, "CREATE TABLE") {
+		if !isColumnLine(line) {
 			continue
 		}
 
@@ -101,15 +127,37 @@ func parseExtraFields(
 		sqlType := strings.ToLower(tokens[1])
 		var goType, goSchemaType string
 		switch {
+		case sqlType == "tinyint(1)",
+			strings.HasPrefix(sqlType, "bool"):
+			goType, goSchemaType = "bool", "bool"
 		case strings.Contains(sqlType, "tinyint"):
 			goType, goSchemaType = "int", "int"
-		case strings.Contains(sqlType, "datetime"):
+		case strings.Contains(sqlType, "bigint") &&
+			strings.Contains(upperLine, "UNSIGNED"):
+			goType, goSchemaType = "uint64", "uint64"
+		case strings.Contains(sqlType, "decimal"),
+			strings.Contains(sqlType, "numeric"):
+			goType, goSchemaType = "string", "decimal"
+		case strings.Contains(sqlType, "float"),
+			strings.Contains(sqlType, "double"),
+			strings.Contains(sqlType, "real"):
+			goType, goSchemaType = "float64", "float64"
+		case strings.Contains(sqlType, "json"):
+			goType, goSchemaType = "json.RawMessage", "json.RawMessage"
+			hasJSON = true
+		case strings.Contains(sqlType, "blob"),
+			strings.Contains(sqlType, "binary"),
+			strings.Contains(sqlType, "bytea"):
+			goType, goSchemaType = "[]byte", "[]byte"
+		case strings.Contains(sqlType, "datetime"),
+			strings.Contains(sqlType, "timestamp"):
 			goType, goSchemaType = "*helper.JSONTime", "*time.Time"
 			hasDateTime = true
 		case strings.Contains(sqlType, "date"):
 			goType, goSchemaType = "*helper.JSONTime", "*time.Time"
 			hasDateTime = true
-		case strings.Contains(sqlType, "int"):
+		case strings.Contains(sqlType, "int"),
+			strings.Contains(sqlType, "serial"):
 			goType, goSchemaType = "int", "int"
 		case strings.Contains(sqlType, "char"),
 			strings.Contains(sqlType, "text"),
@@ -137,7 +185,8 @@ func parseExtraFields(
 		}
 
 		var rules []string
-		if isRequired {
+		// required rejects false, so a NOT NULL flag cannot carry the rule.
+		if isRequired && goType != "bool" {
 			rules = append(rules, "required")
 		}
 		if customRules != "" {
@@ -184,6 +233,117 @@ func parseExtraFields(
 	return
 }
 
+func isIndexLine(upperLine string) bool {
+	for _, prefix := range []string{"PRIMARY", "KEY", "UNIQUE", "INDEX ", "CREATE INDEX", "CREATE UNIQUE INDEX"} {
+		if strings.HasPrefix(upperLine, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isColumnLine(line string) bool {
+	upperLine := strings.ToUpper(line)
+	return line != "" &&
+		!strings.HasPrefix(line, ")") &&
+		!strings.HasPrefix(upperLine, "CREATE ") &&
+		!strings.HasPrefix(upperLine, "FULLTEXT") &&
+		!isIndexLine(upperLine)
+}
+
+// parseIndexedColumns returns the leading column of every key and index in
+// the DDL, as only that column can be looked up through the index alone.
+func parseIndexedColumns(ddl string) []string {
+	indexed := []string{"id"}
+	for _, raw := range strings.Split(ddl, "\n") {
+		line, _, _ := strings.Cut(strings.TrimSpace(raw), "--")
+		upperLine := strings.ToUpper(line)
+
+		var col string
+		switch {
+		case isIndexLine(upperLine):
+			open := strings.Index(line, "(")
+			if open == -1 {
+				continue
+			}
+			cols := strings.FieldsFunc(line[open+1:], func(r rune) bool {
+				return r == ',' || r == '(' || r == ')' || unicode.IsSpace(r)
+			})
+			if len(cols) == 0 {
+				continue
+			}
+			col = cols[0]
+		case isColumnLine(line) &&
+			(strings.Contains(upperLine, " PRIMARY KEY") || strings.Contains(upperLine, " UNIQUE")):
+			col = strings.Fields(line)[0]
+		default:
+			continue
+		}
+
+		col = strings.Trim(col, "`\"")
+		if !slices.Contains(indexed, col) {
+			indexed = append(indexed, col)
+		}
+	}
+	return indexed
+}
+
+// parseQueryColumns makes indexed columns sortable and filterable with the
+// operators an index serves. A column can be opened up regardless with the
+// -- sortable and -- filterable comments, the latter allowing every operator.
+func parseQueryColumns(ddl string) (sortable, filterable string) {
+	indexed := parseIndexedColumns(ddl)
+
+	var sortCols []string
+	var filterCols []string
+	for _, raw := range strings.Split(ddl, "\n") {
+		line := strings.TrimSpace(raw)
+		tokens := strings.Fields(line)
+		if !isColumnLine(line) || len(tokens) < 2 {
+			continue
+		}
+
+		col := strings.Trim(tokens[0], "`\"")
+		isIndexed := slices.Contains(indexed, col)
+		if isIndexed || strings.Contains(raw, "-- sortable") {
+			sortCols = append(sortCols, fmt.Sprintf("\"%s\"", col))
+		}
+		switch {
+		case strings.Contains(raw, "-- filterable"):
+			filterCols = append(filterCols, fmt.Sprintf("\"%s\": nil", col))
+		case isIndexed:
+			filterCols = append(filterCols, fmt.Sprintf("\"%s\": helper.IndexedOperators", col))
+		}
+	}
+
+	sortable = strings.Join(sortCols, ", ")
+	filterable = strings.Join(filterCols, ",\n\t\t")
+	return
+}
+
+// parseSearchColumns returns the columns of the first FULLTEXT index, in
+// index order, which the q param then searches.
+func parseSearchColumns(ddl string) string {
+	for _, raw := range strings.Split(ddl, "\n") {
+		line := strings.TrimSpace(raw)
+		upperLine := strings.ToUpper(line)
+		if !strings.HasPrefix(upperLine, "FULLTEXT") && !strings.HasPrefix(upperLine, "CREATE FULLTEXT") {
+			continue
+		}
+		open, end := strings.Index(line, "("), strings.LastIndex(line, ")")
+		if open == -1 || end < open {
+			continue
+		}
+
+		var cols []string
+		for _, col := range strings.Split(line[open+1:end], ",") {
+			cols = append(cols, fmt.Sprintf("\"%s\"", strings.Trim(strings.TrimSpace(col), "`\"")))
+		}
+		return strings.Join(cols, ", ")
+	}
+	return ""
+}
+
 func main() {
 	domainPtr := flag.String("domain", "", "Name of the domain (e.g., user, role)")
 	flag.Parse()
@@ -204,21 +364,36 @@ func main() {
 		log.Fatalf("Could not extract table name from DDL")
 	}
 
-	extraField, extraColumn, extraValue, sanitize, schema, defaultColsList, hasSanitize, hasDateTime :=
+	extraField, extraColumn, extraValue, sanitize, schema, defaultColsList, hasSanitize, hasDateTime, hasJSON :=
 		parseExtraFields(ddlContent)
 
+	idType, idSchema, autoIncrement := parseIDColumn(ddlContent)
+	sortable, filterable := parseQueryColumns(ddlContent)
+	searchable := parseSearchColumns(ddlContent)
+	if autoIncrement {
+		defaultColsList = strings.TrimSuffix(`"id", `+defaultColsList, ", ")
+	}
+
 	data := DomainData{
-		Domain:      domainCap,
-		DomainLower: domainLower,
-		TableName:   tableName,
-		Fields:      extraField,
-		Columns:     extraColumn,
-		Values:      extraValue,
-		Sanitize:    sanitize,
-		Schema:      schema,
-		HasSanitize: hasSanitize,
-		HasDateTime: hasDateTime,
-		DefaultCols: defaultColsList,
+		Domain:        domainCap,
+		DomainLower:   domainLower,
+		TableName:     tableName,
+		Fields:        extraField,
+		Columns:       extraColumn,
+		Values:        extraValue,
+		Sanitize:      sanitize,
+		Schema:        schema,
+		HasSanitize:   hasSanitize,
+		HasDateTime:   hasDateTime,
+		HasJSON:       hasJSON,
+		DefaultCols:   defaultColsList,
+		IDType:        idType,
+		IDSchema:      idSchema,
+		IntID:         idType == "int64",
+		AutoIncrement: autoIncrement,
+		Sortable:      sortable,
+		Filterable:    filterable,
+		Searchable:    searchable,
 	}
 
 	modelStubPath := filepath.Join("../stubs", "model.stub")
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"github.com/not-empty/grit-microframework-go/app"
+	"github.com/not-empty/grit-microframework-go/app/config"
+)
+
+func main() {
+	app.Bootstrap()
+	app.Purge(config.AppConfig.PurgeBatchSize)
+}
@@ -1,6 +1,6 @@
 CREATE TABLE example (
   id CHAR(26) NOT NULL,
-  name TEXT NOT NULL, -- validate: "min=5" -- sanitize-html
+  name TEXT NOT NULL, -- validate: "min=5" -- sanitize-html -- sortable -- filterable
   age INT NOT NULL, -- validate: "required,number,gt=0,lt=100"
   last_seen DATE DEFAULT NULL,
   last_login DATETIME DEFAULT NULL,
@@ -8,5 +8,7 @@ CREATE TABLE example (
   updated_at DATETIME DEFAULT NULL,
   deleted_at DATETIME DEFAULT NULL,
   PRIMARY KEY (`id`),
+  KEY `idx_example_age` (`age`),
+  FULLTEXT KEY `ft_example_name` (`name`),
   KEY `idx_example_deleted_at` (`deleted_at`) USING BTREE
 ) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS example (
+  id CHAR(26) NOT NULL PRIMARY KEY,
+  name TEXT NOT NULL,
+  age INT NOT NULL,
+  last_seen DATE DEFAULT NULL,
+  last_login DATETIME DEFAULT NULL,
+  created_at DATETIME DEFAULT NULL,
+  updated_at DATETIME DEFAULT NULL,
+  deleted_at DATETIME DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_example_deleted_at ON example (deleted_at);
+
+CREATE INDEX IF NOT EXISTS idx_example_age ON example (age);
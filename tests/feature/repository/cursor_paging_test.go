@@ -0,0 +1,64 @@
+package feature
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/not-empty/grit-microframework-go/app/repository/models"
+)
+
+func TestList_CursorOverSubSecondTimestamps(t *testing.T) {
+	helper.SetDialect(helper.SQLiteDialect{})
+	t.Cleanup(func() { helper.SetDialect(helper.MySQLDialect{}) })
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE example (
+		id TEXT PRIMARY KEY, name TEXT, age INTEGER,
+		last_seen DATETIME, last_login DATETIME,
+		created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)`)
+	require.NoError(t, err)
+
+	repo := repository.NewRepository[*models.Example](db, func() *models.Example {
+		return &models.Example{}
+	})
+	ctx := context.Background()
+
+	start := time.Date(2025, 6, 6, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		at := start.Add(time.Duration(i) * 150 * time.Millisecond)
+		m := &models.Example{ID: fmt.Sprintf("%02d", i), Name: "Example", Age: 30, CreatedAt: &at, UpdatedAt: &at}
+		require.NoError(t, repo.Add(ctx, m))
+	}
+
+	var seen []any
+	var cursor *helper.PageCursor
+	for page := 0; page < 4; page++ {
+		list, err := repo.List(ctx, 2, 0, cursor, "created_at", "desc", []string{"id", "created_at"}, nil)
+		require.NoError(t, err)
+		for _, row := range list {
+			seen = append(seen, row["id"])
+		}
+
+		next, _ := repo.PageCursors(list, 2, cursor, "created_at", "desc", nil)
+		if next == "" {
+			break
+		}
+		c, err := helper.DecodeCursor(next)
+		require.NoError(t, err)
+		cursor = &c
+	}
+
+	require.Equal(t, []any{"05", "04", "03", "02", "01", "00"}, seen)
+}
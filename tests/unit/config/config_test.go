@@ -12,6 +12,7 @@ func TestLoadConfig(t *testing.T) {
 	t.Setenv("APP_LOG", "true")
 	t.Setenv("APP_NO_AUTH", "false")
 	t.Setenv("APP_PORT", "9000")
+	t.Setenv("APP_STRICT_QUERY", "true")
 
 	t.Setenv("DB_DRIVER", "postgres")
 	t.Setenv("DB_HOST", "localhost")
@@ -21,6 +22,9 @@ func TestLoadConfig(t *testing.T) {
 	t.Setenv("DB_PASS", "password")
 	t.Setenv("DB_PORT", "5432")
 	t.Setenv("DB_USER", "admin")
+	t.Setenv("DB_QUERY_TIMEOUT", "5000")
+	t.Setenv("DB_READ_YOUR_WRITES", "2000")
+	t.Setenv("DB_REPLICAS", "replica-1,replica-2")
 
 	t.Setenv("DB_HOST_TEST", "localhost_test")
 	t.Setenv("DB_NAME_TEST", "test_db")
@@ -32,12 +36,18 @@ func TestLoadConfig(t *testing.T) {
 	t.Setenv("JWT_EXPIRE", "7200")
 	t.Setenv("JWT_RENEW", "3600")
 
+	t.Setenv("CURSOR_SECRET", "cursorsecret")
+
+	t.Setenv("PURGE_BATCH_SIZE", "200")
+	t.Setenv("PURGE_INTERVAL", "3600")
+
 	cfg := config.LoadConfig()
 
 	require.Equal(t, "production", cfg.AppEnv)
 	require.True(t, cfg.AppLog)
 	require.False(t, cfg.AppNoAuth)
 	require.Equal(t, "9000", cfg.AppPort)
+	require.True(t, cfg.AppStrictQuery)
 
 	require.Equal(t, "postgres", cfg.DBDriver)
 	require.Equal(t, "localhost", cfg.DBHost)
@@ -47,6 +57,9 @@ func TestLoadConfig(t *testing.T) {
 	require.Equal(t, "password", cfg.DBPass)
 	require.Equal(t, "5432", cfg.DBPort)
 	require.Equal(t, "admin", cfg.DBUser)
+	require.Equal(t, 5000, cfg.DBQueryTimeout)
+	require.Equal(t, 2000, cfg.DBReadYourWrites)
+	require.Equal(t, "replica-1,replica-2", cfg.DBReplicas)
 
 	require.Equal(t, "localhost_test", cfg.DBHostTest)
 	require.Equal(t, "test_db", cfg.DBNameTest)
@@ -57,6 +70,11 @@ func TestLoadConfig(t *testing.T) {
 	require.Equal(t, "supersecret", cfg.JwtAppSecret)
 	require.Equal(t, int64(7200), cfg.JwtExpire)
 	require.Equal(t, int64(3600), cfg.JwtRenew)
+
+	require.Equal(t, "cursorsecret", cfg.CursorSecret)
+
+	require.Equal(t, 200, cfg.PurgeBatchSize)
+	require.Equal(t, 3600, cfg.PurgeInterval)
 }
 
 func TestGetEnvStr(t *testing.T) {
@@ -65,6 +83,13 @@ func TestGetEnvStr(t *testing.T) {
 	require.Equal(t, "default", config.GetEnvStr("NON_EXISTENT_VAR", "default"))
 }
 
+func TestLoadConfig_CursorSecretDoesNotReuseJwtSecret(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "")
+	t.Setenv("JWT_APP_SECRET", "supersecret")
+
+	require.Empty(t, config.LoadConfig().CursorSecret)
+}
+
 func TestGetEnvBool(t *testing.T) {
 	t.Setenv("BOOL_TRUE", "true")
 	t.Setenv("BOOL_ONE", "1")
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/config"
+	"github.com/not-empty/grit-microframework-go/app/controller"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseController_Aggregate(t *testing.T) {
+	fr := &fakeRepository{
+		aggregateResult: []map[string]any{{"field": "a", "count": 2}},
+	}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/aggregate?metric=count&metric=max:id&group_by=field", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Aggregate(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `[{"field":"a","count":2}]`, rr.Body.String())
+	require.Equal(t, []helper.Aggregate{{Func: "count"}, {Func: "max", Field: "id"}}, fr.aggregates)
+	require.Equal(t, []string{"field"}, fr.aggregateGroups)
+	require.Empty(t, rr.Header().Get("X-Truncated"))
+}
+
+func TestBaseController_Aggregate_Truncated(t *testing.T) {
+	rows := make([]map[string]any, 1001)
+	for i := range rows {
+		rows[i] = map[string]any{"field": i, "count": 1}
+	}
+	fr := &fakeRepository{aggregateResult: rows}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/aggregate?group_by=field", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Aggregate(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "true", rr.Header().Get("X-Truncated"))
+	require.Equal(t, 1001, fr.aggregateLimit)
+
+	var body []map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body, 1000)
+}
+
+func TestBaseController_Aggregate_Empty(t *testing.T) {
+	bc := &controller.BaseController[*fakeModel]{Repo: &fakeRepository{}, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/aggregate?group_by=field", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Aggregate(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `[]`, rr.Body.String())
+}
+
+func TestBaseController_Aggregate_BadRequest(t *testing.T) {
+	_ = config.LoadConfig()
+
+	cases := map[string]string{
+		"/fake/aggregate?metric=median:field": "Invalid metric",
+		"/fake/aggregate?metric=sum:field":    "Invalid metric",
+		"/fake/aggregate?group_by=secret":     "Invalid group_by",
+		"/fake/aggregate?filter=secret:eql:1": "Invalid filter",
+		"/fake/aggregate?filter=field:drop:1": "Invalid filter",
+	}
+	for path, message := range cases {
+		fr := &fakeRepository{}
+		bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+
+		bc.Aggregate(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code, path)
+		require.Contains(t, rr.Body.String(), message)
+		require.Nil(t, fr.aggregates)
+	}
+}
+
+func TestBaseController_Aggregate_MethodNotAllowed(t *testing.T) {
+	bc := &controller.BaseController[*fakeModel]{Repo: &fakeRepository{}, Prefix: "/fake"}
+
+	rr := httptest.NewRecorder()
+	bc.Aggregate(rr, httptest.NewRequest(http.MethodPost, "/fake/aggregate", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -2,8 +2,11 @@ package controller
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -11,8 +14,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/not-empty/grit-microframework-go/app/controller"
 	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
 	"github.com/stretchr/testify/require"
 
 	ulidmock "github.com/not-empty/ulid-go-lib/mock"
@@ -73,10 +78,14 @@ type fakeRepository struct {
 
 	deleteCalled bool
 	deleteError  error
+	deleteCtx    context.Context
 
 	undeleteCalled bool
 	undeleteError  error
 
+	purgeCalled bool
+	purgeError  error
+
 	getResult map[string]any
 	getError  error
 
@@ -85,6 +94,8 @@ type fakeRepository struct {
 
 	listActiveResult []map[string]any
 	listActiveError  error
+	listActiveCtx    context.Context
+	listFilters      []helper.Filter
 
 	listDeletedResult []map[string]any
 	listDeletedError  error
@@ -99,64 +110,212 @@ type fakeRepository struct {
 	rawError  error
 
 	bulkAddError error
+	bulkAddIDs   []string
+
+	upsertedModels []*fakeModel
+	upsertError    error
+	upsertCreated  []bool
+
+	bulkIDs     []interface{}
+	bulkCols    []string
+	bulkFilters []helper.Filter
+	bulkResults []repository.BulkResult
+	bulkError   error
+
+	ifMatchETag  string
+	ifMatchError error
+
+	includeNames []string
+	includeError error
+
+	aggregates      []helper.Aggregate
+	aggregateGroups []string
+	aggregateLimit  int
+	aggregateResult []map[string]any
+	aggregateError  error
+
+	countResult int64
+	countError  error
+	countCtx    context.Context
 }
 
 func (fr *fakeRepository) New() *fakeModel {
 	return &fakeModel{}
 }
 
-func (fr *fakeRepository) Add(m *fakeModel) error {
+func (fr *fakeRepository) Add(ctx context.Context, m *fakeModel) error {
 	fr.insertedModel = m
 	return fr.insertedError
 }
 
-func (fr *fakeRepository) Edit(table, pk string, pkVal interface{}, cols []string, vals []interface{}) error {
+func (fr *fakeRepository) Edit(ctx context.Context, table, pk string, pkVal interface{}, cols []string, vals []interface{}) error {
 	fr.updateFieldsCalled = true
 	fr.updateFieldsCols = cols
 	fr.updateFieldsVals = vals
 	return fr.updateFieldsError
 }
 
-func (fr *fakeRepository) Delete(m *fakeModel) error {
+func (fr *fakeRepository) EditIfMatch(ctx context.Context, table, pk string, pkVal interface{}, cols []string, vals []interface{}, etag string) error {
+	fr.ifMatchETag = etag
+	if fr.ifMatchError != nil {
+		return fr.ifMatchError
+	}
+	return fr.Edit(ctx, table, pk, pkVal, cols, vals)
+}
+
+func (fr *fakeRepository) DeleteIfMatch(ctx context.Context, m *fakeModel, etag string) error {
+	fr.ifMatchETag = etag
+	if fr.ifMatchError != nil {
+		return fr.ifMatchError
+	}
+	return fr.Delete(ctx, m)
+}
+
+func (fr *fakeRepository) UndeleteIfMatch(ctx context.Context, m *fakeModel, etag string) error {
+	fr.ifMatchETag = etag
+	if fr.ifMatchError != nil {
+		return fr.ifMatchError
+	}
+	return fr.Undelete(ctx, m)
+}
+
+func (fr *fakeRepository) Delete(ctx context.Context, m *fakeModel) error {
 	fr.deleteCalled = true
+	fr.deleteCtx = ctx
 	return fr.deleteError
 }
 
-func (fr *fakeRepository) Undelete(m *fakeModel) error {
+func (fr *fakeRepository) Undelete(ctx context.Context, m *fakeModel) error {
 	fr.undeleteCalled = true
 	return fr.deleteError
 }
 
-func (fr *fakeRepository) Detail(id interface{}, fields []string) (map[string]any, error) {
+func (fr *fakeRepository) Purge(ctx context.Context, m *fakeModel) error {
+	fr.purgeCalled = true
+	return fr.purgeError
+}
+
+func (fr *fakeRepository) Aggregate(ctx context.Context, aggs []helper.Aggregate, groupBy []string, filters []helper.Filter, limit int) ([]map[string]any, error) {
+	fr.aggregates = aggs
+	fr.aggregateGroups = groupBy
+	fr.aggregateLimit = limit
+	return fr.aggregateResult, fr.aggregateError
+}
+
+func (fr *fakeRepository) Count(ctx context.Context, filters []helper.Filter) (int64, error) {
+	fr.countCtx = ctx
+	return fr.countResult, fr.countError
+}
+
+func (fr *fakeRepository) DeadCount(ctx context.Context, filters []helper.Filter) (int64, error) {
+	return fr.Count(ctx, filters)
+}
+
+func (fr *fakeRepository) BulkCount(ctx context.Context, ids []string) (int64, error) {
+	return fr.Count(ctx, nil)
+}
+
+func (fr *fakeRepository) PageCursors(list []map[string]any, limit int, pageCursor *helper.PageCursor, orderBy, order string, filters []helper.Filter) (string, string) {
+	sort := helper.ParseSort(orderBy, order, []string{"id", "field"}, []string{"id"})
+	return helper.PageCursors(list, limit, pageCursor, sort, filters)
+}
+
+func (fr *fakeRepository) BulkPageCursors(list []map[string]any, ids []string, limit int, pageCursor *helper.PageCursor, orderBy, order string) (string, string) {
+	return fr.PageCursors(list, limit, pageCursor, orderBy, order, nil)
+}
+
+func (fr *fakeRepository) Include(ctx context.Context, records []map[string]any, names []string, fields map[string][]string) error {
+	fr.includeNames = names
+	return fr.includeError
+}
+
+func (fr *fakeRepository) Detail(ctx context.Context, id interface{}, fields []string) (map[string]any, error) {
 	return fr.getResult, fr.getError
 }
 
-func (fr *fakeRepository) DeadDetail(id interface{}, fields []string) (map[string]any, error) {
+func (fr *fakeRepository) DeadDetail(ctx context.Context, id interface{}, fields []string) (map[string]any, error) {
 	return fr.getDeletedResult, fr.getDeletedError
 }
 
-func (fr *fakeRepository) List(limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
+func (fr *fakeRepository) List(ctx context.Context, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
+	fr.listActiveCtx = ctx
+	fr.listFilters = filters
 	return fr.listActiveResult, fr.listActiveError
 }
 
-func (fr *fakeRepository) DeadList(limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
+func (fr *fakeRepository) DeadList(ctx context.Context, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string, filters []helper.Filter) ([]map[string]any, error) {
 	return fr.listDeletedResult, fr.listDeletedError
 }
 
-func (fr *fakeRepository) Bulk(ids []string, limit int, pageCursor *helper.PageCursor, orderBy, order string, fields []string) ([]map[string]any, error) {
+func (fr *fakeRepository) Bulk(ctx context.Context, ids []string, limit, offset int, pageCursor *helper.PageCursor, orderBy, order string, fields []string) ([]map[string]any, error) {
 	return fr.bulkGetResult, fr.bulkGetError
 }
 
-func (fr *fakeRepository) ListOne(orderBy, order string, fields []string, filters []helper.Filter) (map[string]any, error) {
+func (fr *fakeRepository) ListOne(ctx context.Context, orderBy, order string, fields []string, filters []helper.Filter) (map[string]any, error) {
 	return fr.listOneResult, fr.listOneError
 }
 
-func (fr *fakeRepository) Raw(query string, params map[string]any) ([]map[string]any, error) {
+func (fr *fakeRepository) Raw(ctx context.Context, query string, params map[string]any) ([]map[string]any, error) {
 	return fr.rawResult, fr.rawError
 }
 
-func (fr *fakeRepository) BulkAdd(m []*fakeModel) error {
-	return fr.bulkAddError
+func (fr *fakeRepository) BulkAdd(ctx context.Context, m []*fakeModel) error {
+	if fr.bulkAddError != nil {
+		return fr.bulkAddError
+	}
+	for i, id := range fr.bulkAddIDs {
+		m[i].ID = id
+	}
+	return nil
+}
+
+func (fr *fakeRepository) BulkDelete(ctx context.Context, ids []interface{}) ([]repository.BulkResult, error) {
+	fr.bulkIDs = ids
+	return fr.bulkResults, fr.bulkError
+}
+
+func (fr *fakeRepository) BulkEdit(ctx context.Context, ids []interface{}, cols []string, vals []interface{}) ([]repository.BulkResult, error) {
+	fr.bulkIDs = ids
+	fr.bulkCols = cols
+	return fr.bulkResults, fr.bulkError
+}
+
+func (fr *fakeRepository) BulkEditWhere(ctx context.Context, filters []helper.Filter, cols []string, vals []interface{}) (int64, error) {
+	fr.bulkFilters = filters
+	fr.bulkCols = cols
+	var total int64
+	for _, res := range fr.bulkResults {
+		total += res.Affected
+	}
+	return total, fr.bulkError
+}
+
+func (fr *fakeRepository) BulkUndelete(ctx context.Context, ids []interface{}) ([]repository.BulkResult, error) {
+	fr.bulkIDs = ids
+	return fr.bulkResults, fr.bulkError
+}
+
+func (fr *fakeRepository) Upsert(ctx context.Context, m *fakeModel) (bool, error) {
+	fr.upsertedModels = append(fr.upsertedModels, m)
+	return len(fr.upsertCreated) > 0 && fr.upsertCreated[0], fr.upsertError
+}
+
+func (fr *fakeRepository) BulkUpsert(ctx context.Context, m []*fakeModel) ([]bool, error) {
+	fr.upsertedModels = append(fr.upsertedModels, m...)
+	if fr.upsertError != nil {
+		return nil, fr.upsertError
+	}
+	created := make([]bool, len(m))
+	copy(created, fr.upsertCreated)
+	return created, nil
+}
+
+func newFakeController(fr *fakeRepository) *controller.BaseController[*fakeModel] {
+	return &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
 }
 
 func TestNewBaseController(t *testing.T) {
@@ -280,6 +439,105 @@ func TestBaseController_Delete(t *testing.T) {
 	require.True(t, fr.deleteCalled, "Expected Delete to be called in repository")
 }
 
+func TestBaseController_Delete_Reason(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+	req := httptest.NewRequest(http.MethodDelete, "/fake/delete/1?reason=duplicate", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Delete(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, "duplicate", repository.DeleteReason(fr.deleteCtx))
+}
+
+func TestBaseController_Mutation_NoRowsStates(t *testing.T) {
+	cases := map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"missing":         {sql.ErrNoRows, http.StatusNotFound, helper.ErrorCodeNotFound},
+		"already deleted": {repository.ErrAlreadyDeleted, http.StatusConflict, helper.ErrorCodeAlreadyDeleted},
+		"not deleted":     {repository.ErrNotDeleted, http.StatusConflict, helper.ErrorCodeNotDeleted},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fr := &fakeRepository{deleteError: tc.err}
+			bc := &controller.BaseController[*fakeModel]{
+				Repo:   fr,
+				Prefix: "/fake",
+				SetPK:  func(m *fakeModel, id string) { m.ID = id },
+			}
+
+			for _, req := range []*http.Request{
+				httptest.NewRequest(http.MethodDelete, "/fake/delete/1", nil),
+				httptest.NewRequest(http.MethodPatch, "/fake/undelete/1", nil),
+			} {
+				rr := httptest.NewRecorder()
+				if req.Method == http.MethodDelete {
+					bc.Delete(rr, req)
+				} else {
+					bc.Undelete(rr, req)
+				}
+
+				var resp helper.ErrorResponse
+				require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+				require.Equal(t, tc.status, rr.Code)
+				require.Equal(t, tc.code, resp.Code)
+			}
+		})
+	}
+}
+
+func TestBaseController_Undelete_NotSoftDeletable(t *testing.T) {
+	fr := &fakeRepository{deleteError: repository.ErrNotSoftDeletable}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+	req := httptest.NewRequest(http.MethodPatch, "/fake/undelete/1", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Undelete(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestBaseController_Purge(t *testing.T) {
+	cases := map[string]struct {
+		err    error
+		status int
+	}{
+		"purged":             {nil, http.StatusNoContent},
+		"not deleted":        {sql.ErrNoRows, http.StatusNotFound},
+		"not soft deletable": {repository.ErrNotSoftDeletable, http.StatusNotFound},
+		"failure":            {errors.New("boom"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fr := &fakeRepository{purgeError: tc.err}
+			bc := &controller.BaseController[*fakeModel]{
+				Repo:   fr,
+				Prefix: "/fake",
+				SetPK:  func(m *fakeModel, id string) { m.ID = id },
+			}
+			req := httptest.NewRequest(http.MethodDelete, "/fake/purge/1", nil)
+			rr := httptest.NewRecorder()
+
+			bc.Purge(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			require.True(t, fr.purgeCalled)
+		})
+	}
+}
+
 func TestBaseController_Undelete(t *testing.T) {
 	fr := &fakeRepository{}
 	bc := &controller.BaseController[*fakeModel]{
@@ -1738,3 +1996,545 @@ func TestBaseController_BulkAddWithId_Success(t *testing.T) {
 		t.Errorf("Expected generated ID to be 'myId', got %q", ids[0])
 	}
 }
+
+func TestBaseController_BulkAdd_AutoIncrementIDs(t *testing.T) {
+	fr := &fakeRepository{bulkAddIDs: []string{"7", "8"}}
+	bc := newFakeController(fr)
+	bc.IDStrategy = helper.AutoIncrementStrategy{}
+
+	body := `[{"field":"alpha"},{"field":"beta"}]`
+	req := httptest.NewRequest(http.MethodPost, "/fake/bulk_add", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkAdd(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.JSONEq(t, `{"ids":["7","8"]}`, rr.Body.String())
+}
+
+func TestBaseController_Add_DuplicateKey(t *testing.T) {
+	fr := &fakeRepository{
+		insertedError: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+	}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:    fr,
+		Prefix:  "/fake",
+		SetPK:   func(m *fakeModel, id string) { m.ID = id },
+		ULIDGen: &ulidmock.ULIDMock{},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/add", strings.NewReader(`{"field":"value"}`))
+	rr := httptest.NewRecorder()
+
+	bc.Add(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	require.Contains(t, rr.Body.String(), "Duplicate record")
+}
+
+func TestBaseController_BulkAdd_ForeignKeyViolation(t *testing.T) {
+	fr := &fakeRepository{
+		bulkAddError: &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"},
+	}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:    fr,
+		Prefix:  "/fake",
+		SetPK:   func(m *fakeModel, id string) { m.ID = id },
+		ULIDGen: &ulidmock.ULIDMock{},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/bulk_add", strings.NewReader(`[{"field":"value"}]`))
+	rr := httptest.NewRecorder()
+
+	bc.BulkAdd(rr, req)
+
+	require.Equal(t, http.StatusConflict, rr.Code)
+	require.Contains(t, rr.Body.String(), "Related record constraint failed")
+}
+
+func TestBaseController_List_AppliesDomainTimeout(t *testing.T) {
+	fr := &fakeRepository{listActiveResult: []map[string]any{}}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:         fr,
+		Prefix:       "/fake",
+		SetPK:        func(m *fakeModel, id string) { m.ID = id },
+		QueryTimeout: 250 * time.Millisecond,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.NotNil(t, fr.listActiveCtx)
+	deadline, ok := fr.listActiveCtx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(250*time.Millisecond), deadline, 200*time.Millisecond)
+	require.ErrorIs(t, fr.listActiveCtx.Err(), context.Canceled)
+}
+
+func TestBaseController_List_QueryTimeout(t *testing.T) {
+	fr := &fakeRepository{listActiveError: fmt.Errorf("list: %w", context.DeadlineExceeded)}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+
+	require.Equal(t, http.StatusGatewayTimeout, rr.Code)
+	require.Contains(t, rr.Body.String(), "Query timeout")
+}
+
+func TestBaseController_Detail_QueryTimeout(t *testing.T) {
+	fr := &fakeRepository{getError: context.DeadlineExceeded}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/detail/1", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Detail(rr, req)
+
+	require.Equal(t, http.StatusGatewayTimeout, rr.Code)
+}
+
+func TestBaseController_Detail_SetsETag(t *testing.T) {
+	fr := &fakeRepository{
+		getResult: map[string]any{"id": "1", "field": "value", "updated_at": "2025-01-01 10:00:00"},
+	}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/detail/1", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Detail(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, helper.ETag([]any{"1", "value"}), rr.Header().Get("ETag"))
+}
+
+func TestBaseController_Edit_IfMatch(t *testing.T) {
+	current := helper.ETag([]any{"1", "old"})
+	fr := &fakeRepository{
+		getResult: map[string]any{"id": "1", "field": "old", "updated_at": "2025-01-01 10:00:00"},
+	}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/edit/1", strings.NewReader(`{"field":"new"}`))
+	req.Header.Set("If-Match", current)
+	rr := httptest.NewRecorder()
+
+	bc.Edit(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, current, fr.ifMatchETag)
+	require.True(t, fr.updateFieldsCalled)
+}
+
+func TestBaseController_Edit_StaleIfMatch(t *testing.T) {
+	fr := &fakeRepository{
+		getResult: map[string]any{"id": "1", "field": "old", "updated_at": "2025-01-01 10:00:00"},
+	}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/edit/1", strings.NewReader(`{"field":"new"}`))
+	req.Header.Set("If-Match", helper.ETag("2024-12-31 09:00:00"))
+	rr := httptest.NewRecorder()
+
+	bc.Edit(rr, req)
+
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.False(t, fr.updateFieldsCalled)
+}
+
+func TestBaseController_Edit_ChangedConcurrently(t *testing.T) {
+	fr := &fakeRepository{
+		getResult:    map[string]any{"id": "1", "field": "old", "updated_at": "2025-01-01 10:00:00"},
+		ifMatchError: repository.ErrPreconditionFailed,
+	}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/edit/1", strings.NewReader(`{"field":"new"}`))
+	req.Header.Set("If-Match", "*")
+	rr := httptest.NewRecorder()
+
+	bc.Edit(rr, req)
+
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.Contains(t, rr.Body.String(), "Precondition failed")
+}
+
+func TestBaseController_Delete_StaleIfMatch(t *testing.T) {
+	fr := &fakeRepository{ifMatchError: repository.ErrPreconditionFailed}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:   fr,
+		Prefix: "/fake",
+		SetPK:  func(m *fakeModel, id string) { m.ID = id },
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/fake/delete/1", nil)
+	req.Header.Set("If-Match", `"stale"`)
+	rr := httptest.NewRecorder()
+
+	bc.Delete(rr, req)
+
+	require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	require.Equal(t, `"stale"`, fr.ifMatchETag)
+	require.False(t, fr.deleteCalled)
+}
+
+func TestBaseController_RequireIfMatch(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:           fr,
+		Prefix:         "/fake",
+		SetPK:          func(m *fakeModel, id string) { m.ID = id },
+		RequireIfMatch: true,
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/undelete/1", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Undelete(rr, req)
+
+	require.Equal(t, http.StatusPreconditionRequired, rr.Code)
+	require.False(t, fr.undeleteCalled)
+}
+
+func TestBaseController_BulkEdit_IDs(t *testing.T) {
+	fr := &fakeRepository{bulkResults: []repository.BulkResult{{Affected: 1}, {Err: sql.ErrNoRows}}}
+	bc := newFakeController(fr)
+
+	body := `{"ids":["a","b"],"data":{"id":"x","field":"new","unknown":1}}`
+	req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_edit", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkEdit(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"affected":1,"results":[{"id":"a","status":"updated"},{"id":"b","status":"not_found"}]}`, rr.Body.String())
+	require.Equal(t, []interface{}{"a", "b"}, fr.bulkIDs)
+	require.Equal(t, []string{"field", "updated_at"}, fr.bulkCols)
+}
+
+func TestBaseController_BulkEdit_UnchangedRowIsUpdated(t *testing.T) {
+	fr := &fakeRepository{bulkResults: []repository.BulkResult{{Affected: 0}, {Err: repository.ErrAlreadyDeleted}}}
+	bc := newFakeController(fr)
+
+	body := `{"ids":["a","b"],"data":{"field":"same"}}`
+	req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_edit", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkEdit(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"affected":0,"results":[{"id":"a","status":"updated"},{"id":"b","status":"already_deleted"}]}`, rr.Body.String())
+}
+
+func TestBaseController_BulkEdit_Filter(t *testing.T) {
+	fr := &fakeRepository{bulkResults: []repository.BulkResult{{Affected: 7}}}
+	bc := newFakeController(fr)
+
+	body := `{"filter":["field:eql:old"],"data":{"field":"new"}}`
+	req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_edit", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkEdit(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"affected":7}`, rr.Body.String())
+	require.Equal(t, []helper.Filter{{Field: "field", Operator: "eql", Value: "old", Type: "string"}}, fr.bulkFilters)
+}
+
+func TestBaseController_BulkEdit_EmptyFilter(t *testing.T) {
+	fr := &fakeRepository{bulkError: repository.ErrMissingFilter}
+	bc := newFakeController(fr)
+
+	body := `{"filter":["field:eql:old"],"data":{"field":"new"}}`
+	req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_edit", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkEdit(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Invalid filter")
+}
+
+func TestBaseController_BulkEdit_BadRequests(t *testing.T) {
+	cases := map[string]string{
+		"json":           `{`,
+		"no data":        `{"ids":["a"],"data":{"id":"x"}}`,
+		"no ids":         `{"data":{"field":"new"}}`,
+		"ids and filter": `{"ids":["a"],"filter":["field:eql:old"],"data":{"field":"new"}}`,
+		"bad filter":     `{"filter":["secret:eql:1"],"data":{"field":"new"}}`,
+		"bad operator":   `{"filter":["field:drop:1"],"data":{"field":"new"}}`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			fr := &fakeRepository{}
+			bc := newFakeController(fr)
+
+			req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_edit", bytes.NewBufferString(body))
+			rr := httptest.NewRecorder()
+
+			bc.BulkEdit(rr, req)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Nil(t, fr.bulkCols)
+		})
+	}
+}
+
+func TestBaseController_BulkDelete(t *testing.T) {
+	fr := &fakeRepository{bulkResults: []repository.BulkResult{{Affected: 1}}}
+	bc := newFakeController(fr)
+	bc.IDStrategy = helper.ClientIDStrategy{}
+
+	req := httptest.NewRequest(http.MethodDelete, "/fake/bulk_delete", bytes.NewBufferString(`{"ids":["a"," "]}`))
+	rr := httptest.NewRecorder()
+
+	bc.BulkDelete(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"affected":1,"results":[{"id":"a","status":"deleted"},{"id":" ","status":"invalid"}]}`, rr.Body.String())
+	require.Equal(t, []interface{}{"a"}, fr.bulkIDs)
+}
+
+func TestBaseController_BulkUndelete(t *testing.T) {
+	fr := &fakeRepository{bulkResults: []repository.BulkResult{{Affected: 1}, {Affected: 1}}}
+	bc := newFakeController(fr)
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_undelete", bytes.NewBufferString(`{"ids":["a","b"]}`))
+	rr := httptest.NewRecorder()
+
+	bc.BulkUndelete(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"affected":2,"results":[{"id":"a","status":"undeleted"},{"id":"b","status":"undeleted"}]}`, rr.Body.String())
+}
+
+func TestBaseController_BulkUndelete_NotDeleted(t *testing.T) {
+	fr := &fakeRepository{bulkResults: []repository.BulkResult{{Err: repository.ErrNotDeleted}, {Err: sql.ErrNoRows}}}
+	bc := newFakeController(fr)
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/bulk_undelete", bytes.NewBufferString(`{"ids":["a","b"]}`))
+	rr := httptest.NewRecorder()
+
+	bc.BulkUndelete(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"affected":0,"results":[{"id":"a","status":"not_deleted"},{"id":"b","status":"not_found"}]}`, rr.Body.String())
+}
+
+func TestBaseController_BulkMutation_Errors(t *testing.T) {
+	cases := map[string]struct {
+		method string
+		body   string
+		err    error
+		status int
+	}{
+		"method":    {http.MethodPost, `{"ids":["a"]}`, nil, http.StatusMethodNotAllowed},
+		"empty ids": {http.MethodDelete, `{"ids":[]}`, nil, http.StatusBadRequest},
+		"repo":      {http.MethodDelete, `{"ids":["a"]}`, errors.New("boom"), http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bc := newFakeController(&fakeRepository{bulkError: tc.err})
+
+			req := httptest.NewRequest(tc.method, "/fake/bulk_delete", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+
+			bc.BulkDelete(rr, req)
+			require.Equal(t, tc.status, rr.Code)
+		})
+	}
+}
+
+func TestBaseController_Add_PreferRepresentation(t *testing.T) {
+	fr := &fakeRepository{
+		getResult: map[string]any{"id": "abc", "field": "stored"},
+	}
+	bc := newFakeController(fr)
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/add?fields=field", bytes.NewBufferString(`{"id":"abc","field":"value"}`))
+	req.Header.Set("Prefer", helper.PreferRepresentation)
+	rr := httptest.NewRecorder()
+
+	bc.Add(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.Equal(t, helper.PreferRepresentation, rr.Header().Get("Preference-Applied"))
+	require.JSONEq(t, `{"field":"stored"}`, rr.Body.String())
+}
+
+func TestBaseController_Edit_PreferRepresentation(t *testing.T) {
+	fr := &fakeRepository{
+		getResult: map[string]any{"id": "abc", "field": "stored"},
+	}
+	bc := newFakeController(fr)
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/edit/abc", bytes.NewBufferString(`{"field":"value"}`))
+	req.Header.Set("Prefer", helper.PreferRepresentation)
+	rr := httptest.NewRecorder()
+
+	bc.Edit(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, helper.PreferRepresentation, rr.Header().Get("Preference-Applied"))
+	require.Contains(t, rr.Body.String(), `"id":"abc"`)
+}
+
+func TestBaseController_Edit_WithoutPreference(t *testing.T) {
+	fr := &fakeRepository{
+		getResult: map[string]any{"id": "abc", "field": "stored"},
+	}
+	bc := newFakeController(fr)
+
+	req := httptest.NewRequest(http.MethodPatch, "/fake/edit/abc", bytes.NewBufferString(`{"field":"value"}`))
+	req.Header.Set("Prefer", "return=minimal")
+	rr := httptest.NewRecorder()
+
+	bc.Edit(rr, req)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Empty(t, rr.Header().Get("Preference-Applied"))
+}
+
+func TestBaseController_BulkAdd_PreferRepresentation(t *testing.T) {
+	fr := &fakeRepository{
+		bulkGetResult: []map[string]any{
+			{"id": "a", "field": "first"},
+			{"id": "b", "field": "second"},
+		},
+	}
+	bc := newFakeController(fr)
+
+	body := `[{"id":"b","field":"second"},{"id":"a","field":"first"}]`
+	req := httptest.NewRequest(http.MethodPost, "/fake/bulk_add?fields=field", bytes.NewBufferString(body))
+	req.Header.Set("Prefer", helper.PreferRepresentation)
+	rr := httptest.NewRecorder()
+
+	bc.BulkAdd(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.JSONEq(t, `[{"id":"b","field":"second"},{"id":"a","field":"first"}]`, rr.Body.String())
+}
+
+func TestBaseController_Upsert(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := newFakeController(fr)
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/upsert", bytes.NewBufferString(`{"id":"abc","field":"value"}`))
+	rr := httptest.NewRecorder()
+
+	bc.Upsert(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"id":"abc"}`, rr.Body.String())
+	require.Len(t, fr.upsertedModels, 1)
+	require.NotNil(t, fr.upsertedModels[0].CreatedAt)
+	require.NotNil(t, fr.upsertedModels[0].UpdatedAt)
+}
+
+func TestBaseController_Upsert_Created(t *testing.T) {
+	bc := newFakeController(&fakeRepository{upsertCreated: []bool{true}})
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/upsert", bytes.NewBufferString(`{"id":"abc","field":"value"}`))
+	rr := httptest.NewRecorder()
+
+	bc.Upsert(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.JSONEq(t, `{"id":"abc"}`, rr.Body.String())
+}
+
+func TestBaseController_Upsert_Errors(t *testing.T) {
+	cases := map[string]struct {
+		method string
+		body   string
+		err    error
+		status int
+	}{
+		"method":  {http.MethodPut, `{}`, nil, http.StatusMethodNotAllowed},
+		"json":    {http.MethodPost, `{`, nil, http.StatusBadRequest},
+		"invalid": {http.MethodPost, `{"id":"abc"}`, nil, http.StatusUnprocessableEntity},
+		"repo":    {http.MethodPost, `{"id":"abc","field":"value"}`, errors.New("boom"), http.StatusInternalServerError},
+		"deleted": {http.MethodPost, `{"id":"abc","field":"value"}`, repository.ErrAlreadyDeleted, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bc := newFakeController(&fakeRepository{upsertError: tc.err})
+
+			req := httptest.NewRequest(tc.method, "/fake/upsert", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+
+			bc.Upsert(rr, req)
+			require.Equal(t, tc.status, rr.Code)
+		})
+	}
+}
+
+func TestBaseController_BulkUpsert(t *testing.T) {
+	fr := &fakeRepository{upsertCreated: []bool{false, true}}
+	bc := newFakeController(fr)
+	bc.ULIDGen = &ulidmock.ULIDMock{
+		GenerateFunc: func(ts int64) (string, error) {
+			return "generated", nil
+		},
+	}
+
+	body := `[{"id":"abc","field":"one"},{"field":"two"}]`
+	req := httptest.NewRequest(http.MethodPost, "/fake/bulk_upsert", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkUpsert(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"ids":["abc","generated"],"created":[false,true]}`, rr.Body.String())
+	require.Len(t, fr.upsertedModels, 2)
+}
+
+func TestBaseController_BulkUpsert_AllCreated(t *testing.T) {
+	bc := newFakeController(&fakeRepository{upsertCreated: []bool{true, true}})
+
+	body := `[{"id":"abc","field":"one"},{"id":"def","field":"two"}]`
+	req := httptest.NewRequest(http.MethodPost, "/fake/bulk_upsert", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	bc.BulkUpsert(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.JSONEq(t, `{"ids":["abc","def"],"created":[true,true]}`, rr.Body.String())
+}
+
+func TestBaseController_BulkUpsert_Errors(t *testing.T) {
+	cases := map[string]struct {
+		body   string
+		err    error
+		status int
+	}{
+		"empty":   {`[]`, nil, http.StatusBadRequest},
+		"json":    {`{}`, nil, http.StatusBadRequest},
+		"repo":    {`[{"id":"abc","field":"one"}]`, errors.New("boom"), http.StatusInternalServerError},
+		"deleted": {`[{"id":"abc","field":"one"}]`, repository.ErrAlreadyDeleted, http.StatusConflict},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			bc := newFakeController(&fakeRepository{upsertError: tc.err})
+
+			req := httptest.NewRequest(http.MethodPost, "/fake/bulk_upsert", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+
+			bc.BulkUpsert(rr, req)
+			require.Equal(t, tc.status, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/controller"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+
+	ulidmock "github.com/not-empty/ulid-go-lib/mock"
+)
+
+type membershipModel struct {
+	UserID  int64  `json:"user_id"`
+	GroupID string `json:"group_id"`
+	Role    string `json:"role"`
+}
+
+func (m *membershipModel) TableName() string {
+	return "membership"
+}
+
+func (m *membershipModel) Columns() []string {
+	return []string{"user_id", "group_id", "role"}
+}
+
+func (m *membershipModel) Values() []interface{} {
+	return []interface{}{m.UserID, m.GroupID, m.Role}
+}
+
+func (m *membershipModel) HasDefaultValue() []string {
+	return []string{}
+}
+
+func (m *membershipModel) PrimaryKey() string {
+	return "user_id"
+}
+
+func (m *membershipModel) PrimaryKeyValue() interface{} {
+	return m.UserID
+}
+
+func (m *membershipModel) PrimaryKeys() []string {
+	return []string{"user_id", "group_id"}
+}
+
+func (m *membershipModel) PrimaryKeyValues() []interface{} {
+	return []interface{}{m.UserID, m.GroupID}
+}
+
+func (m *membershipModel) Schema() map[string]string {
+	return map[string]string{"user_id": "int", "group_id": "string", "role": "string"}
+}
+
+func newMembershipController(t *testing.T) (*controller.BaseController[*membershipModel], sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo := repository.NewRepository(db, func() *membershipModel {
+		return &membershipModel{}
+	})
+	bc := &controller.BaseController[*membershipModel]{
+		Repo:   repo,
+		Prefix: "/membership",
+		SetKey: func(m *membershipModel, key []interface{}) {
+			m.UserID = key[0].(int64)
+			m.GroupID = key[1].(string)
+		},
+		ULIDGen: &ulidmock.ULIDMock{},
+	}
+	return bc, mock
+}
+
+func TestBaseController_CompositeKey_Detail(t *testing.T) {
+	bc, mock := newMembershipController(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `user_id`, `group_id`, `role` FROM `membership` WHERE `user_id` = ? AND `group_id` = ?")).
+		WithArgs(int64(7), "admins").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "group_id", "role"}).AddRow(7, "admins", "owner"))
+
+	req := httptest.NewRequest(http.MethodGet, "/membership/detail/7/admins?fields=user_id,group_id,role", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Detail(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"user_id":7,"group_id":"admins","role":"owner"}`, rr.Body.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_CompositeKey_InvalidId(t *testing.T) {
+	bc, _ := newMembershipController(t)
+
+	for _, path := range []string{"/membership/detail/7", "/membership/detail/abc/admins"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rr := httptest.NewRecorder()
+
+		bc.Detail(rr, req)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, rr.Body.String(), "Invalid Id")
+	}
+}
+
+func TestBaseController_CompositeKey_AddReturnsKey(t *testing.T) {
+	bc, mock := newMembershipController(t)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `membership` (`user_id`, `group_id`, `role`) VALUES (?, ?, ?)")).
+		WithArgs(int64(7), "admins", "owner").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	body, _ := json.Marshal(map[string]any{"user_id": 7, "group_id": "admins", "role": "owner"})
+	req := httptest.NewRequest(http.MethodPost, "/membership/add", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	bc.Add(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.JSONEq(t, `{"id":{"user_id":7,"group_id":"admins"}}`, rr.Body.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_CompositeKey_ListSetsCursor(t *testing.T) {
+	bc, mock := newMembershipController(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `role`, `user_id`, `group_id` FROM `membership`")).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "user_id", "group_id"}).AddRow("owner", 7, "admins"))
+
+	req := httptest.NewRequest(http.MethodGet, "/membership/list?limit=1&fields=role", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `[{"role":"owner","user_id":7,"group_id":"admins"}]`, rr.Body.String())
+
+	pc, err := helper.DecodeCursor(rr.Header().Get("X-Page-Cursor"))
+	require.NoError(t, err)
+	require.Equal(t, []any{int64(7), "admins"}, pc.Values)
+	require.Equal(t, "user_id:DESC,group_id:DESC", pc.OrderBy)
+	require.Empty(t, rr.Header().Get("X-Prev-Page-Cursor"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_IDStrategy_RejectsMalformedPathID(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:       fr,
+		Prefix:     "/fake",
+		SetPK:      func(m *fakeModel, id string) { m.ID = id },
+		IDStrategy: helper.UUIDv4Strategy{},
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/fake/delete/123", nil)
+	rr := httptest.NewRecorder()
+
+	bc.Delete(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Invalid Id")
+	require.False(t, fr.deleteCalled)
+}
+
+func TestBaseController_IDStrategy_GeneratesID(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{
+		Repo:       fr,
+		Prefix:     "/fake",
+		SetPK:      func(m *fakeModel, id string) { m.ID = id },
+		IDStrategy: helper.UUIDv7Strategy{},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/add", bytes.NewBufferString(`{"field":"value"}`))
+	rr := httptest.NewRecorder()
+
+	bc.Add(rr, req)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, helper.UUIDv7Strategy{}.ValidateID(fr.insertedModel.ID))
+}
+
+func TestBaseController_IDStrategy_ValidatesSuppliedID(t *testing.T) {
+	cases := map[string]struct {
+		strategy helper.IDStrategy
+		body     string
+		message  string
+	}{
+		"malformed": {helper.UUIDv4Strategy{}, `{"id":"nope","field":"value"}`, "Invalid Id"},
+		"missing":   {helper.ClientIDStrategy{}, `{"field":"value"}`, "Missing Id"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fr := &fakeRepository{}
+			bc := &controller.BaseController[*fakeModel]{
+				Repo:       fr,
+				Prefix:     "/fake",
+				SetPK:      func(m *fakeModel, id string) { m.ID = id },
+				IDStrategy: tc.strategy,
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/fake/add", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+
+			bc.Add(rr, req)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.Contains(t, rr.Body.String(), tc.message)
+			require.Nil(t, fr.insertedModel)
+		})
+	}
+}
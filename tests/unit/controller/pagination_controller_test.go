@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/config"
+	"github.com/not-empty/grit-microframework-go/app/controller"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseController_List_WithTotal(t *testing.T) {
+	fr := &fakeRepository{
+		listActiveResult: []map[string]any{{"id": "a"}},
+		countResult:      41,
+	}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list?with_total=true", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "41", rr.Header().Get("X-Total-Count"))
+}
+
+func TestBaseController_List_WithoutTotalSkipsCount(t *testing.T) {
+	fr := &fakeRepository{listActiveResult: []map[string]any{{"id": "a"}}}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	rr := httptest.NewRecorder()
+	bc.List(rr, httptest.NewRequest(http.MethodGet, "/fake/list", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("X-Total-Count"))
+	require.Nil(t, fr.countCtx)
+}
+
+func TestBaseController_DeadListAndBulk_WithTotal(t *testing.T) {
+	fr := &fakeRepository{countResult: 3}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	rr := httptest.NewRecorder()
+	bc.DeadList(rr, httptest.NewRequest(http.MethodGet, "/fake/dead_list?with_total=1", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "3", rr.Header().Get("X-Total-Count"))
+
+	rr = httptest.NewRecorder()
+	bc.Bulk(rr, httptest.NewRequest(http.MethodPost, "/fake/bulk?with_total=1", bytes.NewBufferString(`{"ids":["a","b"]}`)))
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "3", rr.Header().Get("X-Total-Count"))
+}
+
+func TestBaseController_List_CountError(t *testing.T) {
+	_ = config.LoadConfig()
+
+	fr := &fakeRepository{countError: errors.New("boom")}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	rr := httptest.NewRecorder()
+	bc.List(rr, httptest.NewRequest(http.MethodGet, "/fake/list?with_total=true", nil))
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Contains(t, rr.Body.String(), "Count error")
+}
+
+func TestBaseController_List_PageMode(t *testing.T) {
+	bc, mock := newMembershipController(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `role`, `user_id`, `group_id` FROM `membership` ORDER BY `user_id` DESC, `group_id` DESC LIMIT ? OFFSET ?",
+	)).
+		WithArgs(5, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "user_id", "group_id"}).
+			AddRow("owner", 7, "admins").
+			AddRow("member", 6, "admins").
+			AddRow("member", 5, "admins").
+			AddRow("member", 4, "admins").
+			AddRow("member", 3, "admins"))
+
+	req := httptest.NewRequest(http.MethodGet, "/membership/list?limit=5&page=3&fields=role", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, rr.Header().Get("X-Page-Cursor"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_List_PageModeRejected(t *testing.T) {
+	_ = config.LoadConfig()
+
+	cursor := helper.EncodeCursor(helper.PageCursor{Values: []any{"a"}, OrderBy: "id:DESC"})
+	cases := []string{
+		"/fake/list?page=0",
+		"/fake/list?offset=20000",
+		"/fake/list?page=2&page_cursor=" + cursor,
+	}
+	for _, path := range cases {
+		fr := &fakeRepository{}
+		bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake", MaxOffset: 1000}
+
+		rr := httptest.NewRecorder()
+		bc.List(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusBadRequest, rr.Code, path)
+		require.Contains(t, rr.Body.String(), "Invalid Page")
+		require.Nil(t, fr.listActiveCtx)
+	}
+}
+
+func TestBaseController_List_CursorNavigation(t *testing.T) {
+	_ = config.LoadConfig()
+	bc, mock := newMembershipController(t)
+
+	next := helper.EncodeCursor(helper.PageCursor{Values: []any{"owner", int64(7), "admins"}, OrderBy: "role:ASC,user_id:ASC,group_id:ASC"})
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `role`, `user_id`, `group_id` FROM `membership` "+
+			"WHERE ( `role` > ? OR ( `role` = ? AND (`user_id`, `group_id`) > (?, ?) ) ) "+
+			"ORDER BY `role` ASC, `user_id` ASC, `group_id` ASC LIMIT ?",
+	)).
+		WithArgs("owner", "owner", 7, "admins", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "user_id", "group_id"}).
+			AddRow("viewer", 8, "admins").
+			AddRow("viewer", 9, "admins"))
+
+	req := httptest.NewRequest(http.MethodGet, "/membership/list?limit=2&order_by=role&order=asc&fields=role&page_cursor="+next, nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	pc, err := helper.DecodeCursor(rr.Header().Get("X-Page-Cursor"))
+	require.NoError(t, err)
+	require.Equal(t, []any{"viewer", int64(9), "admins"}, pc.Values)
+
+	prev, err := helper.DecodeCursor(rr.Header().Get("X-Prev-Page-Cursor"))
+	require.NoError(t, err)
+	require.True(t, prev.Backward)
+	require.Equal(t, []any{"viewer", int64(8), "admins"}, prev.Values)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_List_CursorForOtherOrder(t *testing.T) {
+	_ = config.LoadConfig()
+	bc, mock := newMembershipController(t)
+
+	cursor := helper.EncodeCursor(helper.PageCursor{Values: []any{"owner", int64(7), "admins"}, OrderBy: "role:ASC,user_id:ASC,group_id:ASC"})
+	req := httptest.NewRequest(http.MethodGet, "/membership/list?order_by=role&order=desc&page_cursor="+cursor, nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Invalid Page Cursor")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_List_SortsOnSeveralColumns(t *testing.T) {
+	bc, mock := newMembershipController(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `role`, `user_id`, `group_id` FROM `membership` " +
+			"ORDER BY `role` DESC, `group_id` ASC, `user_id` ASC LIMIT ?",
+	)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "user_id", "group_id"}).AddRow("viewer", 8, "admins"))
+
+	req := httptest.NewRequest(http.MethodGet, "/membership/list?limit=1&order_by=role:desc,group_id&order=asc&fields=role", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	pc, err := helper.DecodeCursor(rr.Header().Get("X-Page-Cursor"))
+	require.NoError(t, err)
+	require.Equal(t, "role:DESC,group_id:ASC,user_id:ASC", pc.OrderBy)
+	require.Equal(t, []any{"viewer", "admins", int64(8)}, pc.Values)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/config"
+	"github.com/not-empty/grit-microframework-go/app/controller"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseController_StrictQuery_ListsProblems(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake", StrictQuery: true}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list?order_by=secret&limit=abc&filter=field:btw:1&filter=field:eql:ok", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var body helper.QueryErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, "Invalid query", body.Error)
+	require.Equal(t, []string{"order_by", "limit", "filter"}, []string{body.Problems[0].Param, body.Problems[1].Param, body.Problems[2].Param})
+	require.Len(t, body.Problems, 3)
+	require.Nil(t, fr.listActiveCtx)
+}
+
+func TestBaseController_StrictQuery_OtherEndpoints(t *testing.T) {
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake", StrictQuery: true}
+
+	handlers := map[string]http.HandlerFunc{
+		"/fake/list_one?order=sideways": bc.ListOne,
+		"/fake/dead_list?fields=secret": bc.DeadList,
+		"/fake/detail/1?fields=secret":  bc.Detail,
+		"/fake/aggregate?filter=nope":   bc.Aggregate,
+	}
+	for path, handler := range handlers {
+		rr := httptest.NewRecorder()
+		handler(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusBadRequest, rr.Code, path)
+		require.Contains(t, rr.Body.String(), "Invalid query", path)
+	}
+}
+
+func TestBaseController_LenientQuery_SkipsProblems(t *testing.T) {
+	fr := &fakeRepository{listActiveResult: []map[string]any{{"id": "a"}}}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list?order_by=secret&filter=field:btw:1&filter=field:eql:ok", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, []helper.Filter{{Field: "field", Operator: "eql", Value: "ok", Type: "string"}}, fr.listFilters)
+}
+
+type indexedModel struct {
+	fakeModel
+}
+
+func (m *indexedModel) SortableColumns() []string {
+	return []string{}
+}
+
+func (m *indexedModel) FilterableColumns() map[string][]string {
+	return map[string][]string{"id": {"eql", "in"}}
+}
+
+func TestBaseController_QueryLimits_AlwaysRejected(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	bc := &controller.BaseController[*indexedModel]{
+		Repo: repository.NewRepository(db, func() *indexedModel {
+			return &indexedModel{}
+		}),
+		Prefix:      "/indexed",
+		MaxInValues: 2,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/indexed/list?order_by=field&filter=field:eql:x&filter=id:lik:a&filter=id:in:1,2,3&filter=nope:eql:1", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	var body helper.QueryErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, []helper.QueryProblem{
+		{Param: "order_by", Value: "field", Message: `field "field" is not sortable`},
+		{Param: "filter", Value: "field:eql:x", Message: `filter not allowed: field "field" is not filterable`},
+		{Param: "filter", Value: "id:lik:a", Message: `filter not allowed: operator "lik" is not allowed on "id"`},
+		{Param: "filter", Value: "id:in:1,2,3", Message: "filter not allowed: in takes at most 2 values, got 3"},
+	}, body.Problems)
+}
+
+func TestBaseController_Bulk_TooManyIDs(t *testing.T) {
+	_ = config.LoadConfig()
+
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake", MaxBulkIDs: 2}
+
+	req := httptest.NewRequest(http.MethodPost, "/fake/bulk", strings.NewReader(`{"ids":["a","b","c"]}`))
+	rr := httptest.NewRecorder()
+
+	bc.Bulk(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "between 1 and 2 items")
+}
+
+type searchableModel struct {
+	fakeModel
+}
+
+func (m *searchableModel) SearchableColumns() []string {
+	return []string{"field"}
+}
+
+func TestBaseController_Search_NotSearchable(t *testing.T) {
+	_ = config.LoadConfig()
+
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list?q=go", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Invalid search")
+	require.Nil(t, fr.listFilters)
+}
+
+func TestBaseController_Search_OrderByRelevance(t *testing.T) {
+	_ = config.LoadConfig()
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	bc := &controller.BaseController[*searchableModel]{
+		Repo: repository.NewRepository(db, func() *searchableModel {
+			return &searchableModel{}
+		}),
+		Prefix:      "/searchable",
+		StrictQuery: true,
+	}
+
+	match := "MATCH (`field`) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `field`, "+match+" AS `relevance` FROM `fake` "+
+			"WHERE `field` = ? AND "+match+" ORDER BY `relevance` DESC, `id` DESC LIMIT ?",
+	)).
+		WithArgs("go", "x", "go", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "field", "relevance"}).AddRow("1", "x", 0.25))
+
+	req := httptest.NewRequest(http.MethodGet, "/searchable/list_one?q=go&order_by=relevance&filter=field:eql:x&fields=id,field", nil)
+	rr := httptest.NewRecorder()
+
+	bc.ListOne(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"id":"1","field":"x"}`, rr.Body.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/config"
+	"github.com/not-empty/grit-microframework-go/app/controller"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type bookModel struct {
+	ID       string `json:"id"`
+	AuthorID string `json:"author_id"`
+	Title    string `json:"title"`
+}
+
+func (m *bookModel) TableName() string {
+	return "book"
+}
+
+func (m *bookModel) Columns() []string {
+	return []string{"id", "author_id", "title"}
+}
+
+func (m *bookModel) Values() []interface{} {
+	return []interface{}{m.ID, m.AuthorID, m.Title}
+}
+
+func (m *bookModel) HasDefaultValue() []string {
+	return []string{}
+}
+
+func (m *bookModel) PrimaryKey() string {
+	return "id"
+}
+
+func (m *bookModel) PrimaryKeyValue() interface{} {
+	return m.ID
+}
+
+func (m *bookModel) Relations() []repository.Relation {
+	return []repository.Relation{repository.BelongsTo("author", "ctl_author", "author_id")}
+}
+
+func (m *bookModel) Schema() map[string]string {
+	return map[string]string{"id": "string", "author_id": "string", "title": "string"}
+}
+
+type authorModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (m *authorModel) TableName() string {
+	return "author"
+}
+
+func (m *authorModel) Columns() []string {
+	return []string{"id", "name"}
+}
+
+func (m *authorModel) Values() []interface{} {
+	return []interface{}{m.ID, m.Name}
+}
+
+func (m *authorModel) HasDefaultValue() []string {
+	return []string{}
+}
+
+func (m *authorModel) PrimaryKey() string {
+	return "id"
+}
+
+func (m *authorModel) PrimaryKeyValue() interface{} {
+	return m.ID
+}
+
+func (m *authorModel) Relations() []repository.Relation {
+	books := repository.HasMany("books", "ctl_book", "author_id")
+	books.MaxRows = 1
+	return []repository.Relation{books}
+}
+
+func (m *authorModel) Schema() map[string]string {
+	return map[string]string{"id": "string", "name": "string"}
+}
+
+func TestBaseController_List_IncludeBelongsTo(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository.RegisterRepository("ctl_author", repository.NewRepository(db, func() *authorModel {
+		return &authorModel{}
+	}))
+	bc := &controller.BaseController[*bookModel]{
+		Repo: repository.NewRepository(db, func() *bookModel {
+			return &bookModel{}
+		}),
+		Prefix: "/book",
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `title`, `id`, `author_id` FROM `book`")).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "id", "author_id"}).
+			AddRow("Go", "b1", "a1").
+			AddRow("SQL", "b2", "a1"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `name`, `id` FROM `author` WHERE `id` IN (?) ORDER BY `id`")).
+		WithArgs("a1").
+		WillReturnRows(sqlmock.NewRows([]string{"name", "id"}).AddRow("Ann", "a1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/book/list?fields=title&include=author&fields[author]=name", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `[
+		{"title":"Go","id":"b1","author":{"name":"Ann","id":"a1"}},
+		{"title":"SQL","id":"b2","author":{"name":"Ann","id":"a1"}}
+	]`, rr.Body.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBaseController_Include_Unknown(t *testing.T) {
+	_ = config.LoadConfig()
+
+	fr := &fakeRepository{}
+	bc := &controller.BaseController[*fakeModel]{Repo: fr, Prefix: "/fake"}
+
+	req := httptest.NewRequest(http.MethodGet, "/fake/list?include=owner", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Invalid include")
+	require.Nil(t, fr.includeNames)
+}
+
+func TestBaseController_List_IncludeTooLarge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository.RegisterRepository("ctl_book", repository.NewRepository(db, func() *bookModel {
+		return &bookModel{}
+	}))
+	bc := &controller.BaseController[*authorModel]{
+		Repo: repository.NewRepository(db, func() *authorModel {
+			return &authorModel{}
+		}),
+		Prefix: "/author",
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `author`")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("a1", "Ann"))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `book` WHERE `author_id` IN (?) ORDER BY `id` LIMIT ?")).
+		WithArgs("a1", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "title"}).
+			AddRow("b1", "a1", "Go").
+			AddRow("b2", "a1", "SQL"))
+
+	req := httptest.NewRequest(http.MethodGet, "/author/list?include=books", nil)
+	rr := httptest.NewRecorder()
+
+	bc.List(rr, req)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Contains(t, rr.Body.String(), "Include too large")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+var aggregateSchema = map[string]string{
+	"status":      "string",
+	"total":       "decimal",
+	"qty":         "int",
+	"customer_id": "string",
+}
+
+func TestParseAggregates(t *testing.T) {
+	allowed := []string{"status", "total", "qty", "customer_id"}
+
+	aggs, err := helper.ParseAggregates([]string{"count,sum:total", "avg:qty", "count_distinct:customer_id", "sum:total"}, allowed, aggregateSchema)
+	require.NoError(t, err)
+	require.Equal(t, []helper.Aggregate{
+		{Func: "count"},
+		{Func: "sum", Field: "total"},
+		{Func: "avg", Field: "qty"},
+		{Func: "count_distinct", Field: "customer_id"},
+	}, aggs)
+
+	aggs, err = helper.ParseAggregates(nil, allowed, aggregateSchema)
+	require.NoError(t, err)
+	require.Equal(t, []helper.Aggregate{{Func: "count"}}, aggs)
+}
+
+func TestParseAggregates_Invalid(t *testing.T) {
+	allowed := []string{"status", "total"}
+
+	for _, raw := range []string{"median:total", "sum", "sum:status", "max:secret", "avg:qty"} {
+		_, err := helper.ParseAggregates([]string{raw}, allowed, aggregateSchema)
+		require.ErrorIs(t, err, helper.ErrInvalidAggregate, raw)
+	}
+}
+
+func TestAggregate_ExpressionAndType(t *testing.T) {
+	d := helper.MySQLDialect{}
+
+	require.Equal(t, "COUNT(*)", helper.Aggregate{Func: "count"}.Expression(d))
+	require.Equal(t, "COUNT(DISTINCT `customer_id`)", helper.Aggregate{Func: "count_distinct", Field: "customer_id"}.Expression(d))
+	require.Equal(t, "MAX(`total`)", helper.Aggregate{Func: "max", Field: "total"}.Expression(d))
+	require.Equal(t, "sum_total", helper.Aggregate{Func: "sum", Field: "total"}.Alias())
+
+	require.Equal(t, "int", helper.Aggregate{Func: "count_distinct", Field: "status"}.SchemaType(aggregateSchema))
+	require.Equal(t, "decimal", helper.Aggregate{Func: "avg", Field: "total"}.SchemaType(aggregateSchema))
+	require.Equal(t, "float64", helper.Aggregate{Func: "avg", Field: "qty"}.SchemaType(aggregateSchema))
+	require.Equal(t, "string", helper.Aggregate{Func: "min", Field: "status"}.SchemaType(aggregateSchema))
+}
+
+func TestParseGroupBy(t *testing.T) {
+	cols, err := helper.ParseGroupBy("status, customer_id,status", []string{"status", "customer_id"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"status", "customer_id"}, cols)
+
+	cols, err = helper.ParseGroupBy("", []string{"status"})
+	require.NoError(t, err)
+	require.Nil(t, cols)
+
+	_, err = helper.ParseGroupBy("status,secret", []string{"status"})
+	require.ErrorIs(t, err, helper.ErrInvalidAggregate)
+}
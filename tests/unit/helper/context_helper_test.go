@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/not-empty/grit-microframework-go/app/config"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryContext_UsesExplicitTimeout(t *testing.T) {
+	config.AppConfig = &config.Config{DBQueryTimeout: 60000}
+
+	ctx, cancel := helper.QueryContext(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(50*time.Millisecond), deadline, 40*time.Millisecond)
+}
+
+func TestQueryContext_FallsBackToConfig(t *testing.T) {
+	config.AppConfig = &config.Config{DBQueryTimeout: 2000}
+
+	ctx, cancel := helper.QueryContext(context.Background(), 0)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(2*time.Second), deadline, 100*time.Millisecond)
+}
+
+func TestQueryContext_NoTimeout(t *testing.T) {
+	config.AppConfig = &config.Config{DBQueryTimeout: 0}
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := helper.QueryContext(parent, 0)
+	defer cancel()
+
+	_, ok := ctx.Deadline()
+	require.False(t, ok)
+
+	cancelParent()
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestIsTimeoutError(t *testing.T) {
+	ctx := context.Background()
+	require.True(t, helper.IsTimeoutError(ctx, context.DeadlineExceeded))
+	require.True(t, helper.IsTimeoutError(ctx, fmt.Errorf("query: %w", context.DeadlineExceeded)))
+	require.False(t, helper.IsTimeoutError(ctx, context.Canceled))
+	require.False(t, helper.IsTimeoutError(ctx, errors.New("boom")))
+	require.False(t, helper.IsTimeoutError(ctx, nil))
+}
+
+func TestIsTimeoutError_ExpiredContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	require.True(t, helper.IsTimeoutError(ctx, errors.New("pq: canceling statement due to user request")))
+	require.False(t, helper.IsTimeoutError(ctx, nil))
+}
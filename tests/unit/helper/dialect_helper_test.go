@@ -0,0 +1,185 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+type tidbDialect struct {
+	helper.MySQLDialect
+}
+
+func (tidbDialect) Name() string {
+	return "tidb"
+}
+
+func TestGetDialect(t *testing.T) {
+	d, ok := helper.GetDialect("")
+	require.True(t, ok)
+	require.Equal(t, "mysql", d.Name())
+
+	d, ok = helper.GetDialect("PostgreSQL")
+	require.True(t, ok)
+	require.Equal(t, "postgres", d.Name())
+
+	_, ok = helper.GetDialect("oracle")
+	require.False(t, ok)
+}
+
+func TestRegisterDialect(t *testing.T) {
+	helper.RegisterDialect("custom-tidb", tidbDialect{})
+
+	d, ok := helper.GetDialect("custom-tidb")
+	require.True(t, ok)
+	require.Equal(t, "tidb", d.Name())
+	require.Equal(t, "`id`", d.QuoteIdentifier("id"))
+}
+
+func TestSetDialect(t *testing.T) {
+	require.Equal(t, "mysql", helper.CurrentDialect().Name())
+
+	helper.SetDialect(helper.PostgresDialect{})
+	require.Equal(t, "postgres", helper.CurrentDialect().Name())
+
+	helper.SetDialect(nil)
+	require.Equal(t, "mysql", helper.CurrentDialect().Name())
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	my := helper.MySQLDialect{}
+	require.Equal(t, "`name`", my.QuoteIdentifier("name"))
+	require.Equal(t, "`example`", my.QuoteIdentifier("`example`"))
+	require.Equal(t, "`public`.`example`", my.QuoteIdentifier("public.example"))
+	require.Equal(t, "`we``ird`", my.QuoteIdentifier("we`ird"))
+
+	pg := helper.PostgresDialect{}
+	require.Equal(t, `"name"`, pg.QuoteIdentifier("name"))
+	require.Equal(t, `"example"`, pg.QuoteIdentifier("`example`"))
+	require.Equal(t, `"public"."example"`, pg.QuoteIdentifier("`public`.`example`"))
+
+	require.Equal(t, []string{`"id"`, `"age"`}, helper.QuoteIdentifiers(pg, []string{"id", "age"}))
+}
+
+func TestPlaceholderAndRebind(t *testing.T) {
+	query := "SELECT * FROM t WHERE a = ? AND b = '?' AND c IN (?, ?)"
+
+	my := helper.MySQLDialect{}
+	require.Equal(t, "?", my.Placeholder(3))
+	require.Equal(t, query, helper.Rebind(my, query))
+
+	pg := helper.PostgresDialect{}
+	require.Equal(t, "$3", pg.Placeholder(3))
+	require.Equal(t,
+		"SELECT * FROM t WHERE a = $1 AND b = '?' AND c IN ($2, $3)",
+		helper.Rebind(pg, query),
+	)
+}
+
+func TestLimitAndTimestamp(t *testing.T) {
+	my := helper.MySQLDialect{}
+	require.Equal(t, "LIMIT ?", my.Limit("?", ""))
+	require.Equal(t, "LIMIT 10 OFFSET 20", my.Limit("10", "20"))
+	require.Equal(t, "NOW()", my.CurrentTimestamp())
+
+	pg := helper.PostgresDialect{}
+	require.Equal(t, "LIMIT ? OFFSET ?", pg.Limit("?", "?"))
+	require.Equal(t, "CURRENT_TIMESTAMP", pg.CurrentTimestamp())
+}
+
+func TestUpsert(t *testing.T) {
+	my := helper.MySQLDialect{}
+	require.Equal(t,
+		"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `age` = VALUES(`age`)",
+		my.Upsert([]string{"id"}, []string{"name", "age"}),
+	)
+	require.Equal(t, "ON DUPLICATE KEY UPDATE `id` = VALUES(`id`)", my.Upsert([]string{"id"}, nil))
+
+	pg := helper.PostgresDialect{}
+	require.Equal(t,
+		`ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`,
+		pg.Upsert([]string{"id"}, []string{"name"}),
+	)
+	require.Equal(t, `ON CONFLICT ("a", "b") DO NOTHING`, pg.Upsert([]string{"a", "b"}, nil))
+}
+
+func TestClassifyError_MySQL(t *testing.T) {
+	my := helper.MySQLDialect{}
+	cases := map[uint16]helper.DBErrorKind{
+		1062: helper.DBErrorDuplicate,
+		1452: helper.DBErrorForeignKey,
+		1048: helper.DBErrorNotNull,
+		1213: helper.DBErrorDeadlock,
+		1205: helper.DBErrorLockTimeout,
+		1146: helper.DBErrorUnknown,
+	}
+	for number, kind := range cases {
+		err := fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: number})
+		require.Equal(t, kind, my.ClassifyError(err), "mysql error %d", number)
+	}
+	require.Equal(t, helper.DBErrorUnknown, my.ClassifyError(errors.New("boom")))
+}
+
+func TestClassifyError_Postgres(t *testing.T) {
+	pg := helper.PostgresDialect{}
+	cases := map[pq.ErrorCode]helper.DBErrorKind{
+		"23505": helper.DBErrorDuplicate,
+		"23503": helper.DBErrorForeignKey,
+		"23502": helper.DBErrorNotNull,
+		"40P01": helper.DBErrorDeadlock,
+		"55P03": helper.DBErrorLockTimeout,
+		"42P01": helper.DBErrorUnknown,
+	}
+	for code, kind := range cases {
+		require.Equal(t, kind, pg.ClassifyError(&pq.Error{Code: code}), "postgres error %s", code)
+	}
+	require.Equal(t, helper.DBErrorUnknown, pg.ClassifyError(errors.New("boom")))
+}
+
+func TestClassifyDBError_UsesCurrentDialect(t *testing.T) {
+	require.Equal(t, helper.DBErrorUnknown, helper.ClassifyDBError(nil))
+	require.Equal(t, helper.DBErrorDuplicate, helper.ClassifyDBError(&mysql.MySQLError{Number: 1062}))
+}
+
+func TestSQLiteDialect(t *testing.T) {
+	d, ok := helper.GetDialect("sqlite")
+	require.True(t, ok)
+	require.Equal(t, "sqlite3", d.Name())
+
+	require.Equal(t, `"example"`, d.QuoteIdentifier("`example`"))
+	require.Equal(t, "?", d.Placeholder(2))
+	require.Equal(t, "LIMIT ?", d.Limit("?", ""))
+	require.Equal(t, "CURRENT_TIMESTAMP", d.CurrentTimestamp())
+	require.False(t, d.SupportsDefaultKeyword())
+	require.Equal(t,
+		`ON CONFLICT ("id") DO UPDATE SET "name" = excluded."name"`,
+		d.Upsert([]string{"id"}, []string{"name"}),
+	)
+	require.Equal(t, `ON CONFLICT ("id") DO NOTHING`, d.Upsert([]string{"id"}, nil))
+}
+
+func TestClassifyError_SQLite(t *testing.T) {
+	d := helper.SQLiteDialect{}
+	cases := map[string]helper.DBErrorKind{
+		"UNIQUE constraint failed: example.id":      helper.DBErrorDuplicate,
+		"PRIMARY KEY constraint failed: example.id": helper.DBErrorDuplicate,
+		"FOREIGN KEY constraint failed":             helper.DBErrorForeignKey,
+		"NOT NULL constraint failed: example.name":  helper.DBErrorNotNull,
+		"database is locked":                        helper.DBErrorLockTimeout,
+		"no such table: example":                    helper.DBErrorUnknown,
+	}
+	for msg, kind := range cases {
+		require.Equal(t, kind, d.ClassifyError(errors.New(msg)), msg)
+	}
+}
+
+func TestDialect_ForUpdate(t *testing.T) {
+	require.Equal(t, "FOR UPDATE", helper.MySQLDialect{}.ForUpdate())
+	require.Equal(t, "FOR UPDATE", helper.PostgresDialect{}.ForUpdate())
+	require.Equal(t, "", helper.SQLiteDialect{}.ForUpdate())
+}
@@ -72,3 +72,15 @@ func TestJSONError_WithError_Local(t *testing.T) {
 	require.Equal(t, "error", resp.Error)
 	require.Equal(t, "something went wrong", resp.Detail)
 }
+
+func TestJSONErrorCode(t *testing.T) {
+	os.Setenv("APP_ENV", "prod")
+
+	_ = config.LoadConfig()
+
+	rec := httptest.NewRecorder()
+	helper.JSONErrorCode(rec, http.StatusConflict, helper.ErrorCodeAlreadyDeleted, "Already deleted", errors.New("hidden"))
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.JSONEq(t, `{"error":"Already deleted","code":"already_deleted","detail":""}`, rec.Body.String())
+}
@@ -0,0 +1,28 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETag(t *testing.T) {
+	etag := helper.ETag(3)
+	require.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+	require.Equal(t, etag, helper.ETag(3))
+	require.NotEqual(t, etag, helper.ETag(4))
+	require.Equal(t, helper.ETag("2025-01-01 10:00:00"), helper.ETag("2025-01-01 10:00:00"))
+}
+
+func TestMatchETag(t *testing.T) {
+	etag := helper.ETag(3)
+
+	require.True(t, helper.MatchETag(etag, etag))
+	require.False(t, helper.MatchETag("W/"+etag, etag))
+	require.True(t, helper.MatchETag("W/"+etag+", "+etag, etag))
+	require.True(t, helper.MatchETag(`"other", `+etag, etag))
+	require.True(t, helper.MatchETag("*", etag))
+	require.False(t, helper.MatchETag(helper.ETag(2), etag))
+	require.False(t, helper.MatchETag("", etag))
+}
@@ -149,6 +149,13 @@ func TestEnsurePaginationFields_AllFieldsAlreadyPresent(t *testing.T) {
 	require.ElementsMatch(t, []string{"name", "id", "created_at", "email"}, result)
 }
 
+func TestEnsureKeyFields_CompositeKey(t *testing.T) {
+	result := helper.EnsureKeyFields([]string{"role", "group_id"}, []string{"user_id", "group_id"}, "created_at")
+	require.Equal(t, []string{"role", "group_id", "user_id", "created_at"}, result)
+
+	require.Empty(t, helper.EnsureKeyFields(nil, []string{"user_id", "group_id"}, "role"))
+}
+
 func TestGetFieldsParamList_AddsIdAndOrderBy(t *testing.T) {
 	req := &http.Request{URL: &url.URL{RawQuery: "fields=name,email"}}
 	allowed := []string{"id", "name", "email", "created_at"}
@@ -191,3 +198,34 @@ func TestGetFieldsParamList_AllInvalidReturnsNil(t *testing.T) {
 	fields := helper.GetFieldsParamList(req, allowed, "name")
 	require.Nil(t, fields)
 }
+
+func TestEscapeField_FollowsDialect(t *testing.T) {
+	require.Equal(t, "`name`", helper.EscapeField("name"))
+
+	helper.SetDialect(helper.PostgresDialect{})
+	defer helper.SetDialect(helper.MySQLDialect{})
+
+	require.Equal(t, `"name"`, helper.EscapeField("name"))
+	require.Equal(t, []string{`"id"`, `"name"`}, helper.EscapeFields([]string{"id", "name"}))
+	require.Equal(t, "`name`", helper.EscapeMysqlField("name"))
+}
+
+func TestGetIncludeParams(t *testing.T) {
+	r := &http.Request{URL: &url.URL{RawQuery: url.Values{
+		"include":          {"customer, items,,customer"},
+		"fields[customer]": {"id, name"},
+	}.Encode()}}
+
+	names, fields := helper.GetIncludeParams(r)
+	require.Equal(t, []string{"customer", "items"}, names)
+	require.Equal(t, map[string][]string{"customer": {"id", "name"}}, fields)
+
+	names, fields = helper.GetIncludeParams(&http.Request{URL: &url.URL{}})
+	require.Nil(t, names)
+	require.Nil(t, fields)
+}
+
+func TestEnsureKeyFields_SortTerms(t *testing.T) {
+	result := helper.EnsureKeyFields([]string{"name"}, []string{"id"}, "status:asc,created_at:desc")
+	require.Equal(t, []string{"name", "id", "status", "created_at"}, result)
+}
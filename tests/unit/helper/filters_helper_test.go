@@ -3,7 +3,9 @@ package helper
 import (
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/not-empty/grit-microframework-go/app/helper"
 	"github.com/stretchr/testify/require"
@@ -48,6 +50,22 @@ func TestGetFilters_NotAllowed(t *testing.T) {
 	require.Empty(t, result)
 }
 
+func TestParseFilters(t *testing.T) {
+	allowed := []string{"name", "age"}
+
+	filters, err := helper.ParseFilters([]string{"name:EQL: John ", "age:in:1,2"}, helper.AllowColumns(allowed), nil)
+	require.NoError(t, err)
+	require.Equal(t, []helper.Filter{
+		{Field: "name", Operator: "eql", Value: "John"},
+		{Field: "age", Operator: "in", Value: "1,2"},
+	}, filters)
+
+	for _, raw := range []string{"invalid", "secret:eql:1", "name:drop:1"} {
+		_, err := helper.ParseFilters([]string{"age:gt:1", raw}, helper.AllowColumns(allowed), nil)
+		require.ErrorIs(t, err, helper.ErrInvalidFilter, raw)
+	}
+}
+
 func TestBuildWhereClause_AllOperators(t *testing.T) {
 	filters := []helper.Filter{
 		{Field: "name", Operator: "eql", Value: "John"},
@@ -85,3 +103,162 @@ func TestBuildWhereClause_Empty(t *testing.T) {
 	require.Equal(t, "", where)
 	require.Empty(t, args)
 }
+
+func TestBuildWhereClause_Postgres(t *testing.T) {
+	helper.SetDialect(helper.PostgresDialect{})
+	defer helper.SetDialect(helper.MySQLDialect{})
+
+	where, args := helper.BuildWhereClause([]helper.Filter{
+		{Field: "name", Operator: "eql", Value: "John"},
+		{Field: "role", Operator: "in", Value: "admin,user"},
+	})
+	require.Equal(t, `WHERE "name" = ? AND "role" IN (?,?)`, where)
+	require.Len(t, args, 3)
+}
+
+func TestGetFilters_Groups(t *testing.T) {
+	req := &http.Request{
+		URL: &url.URL{RawQuery: url.Values{"filter": {
+			"or(age:gt:30,name:lik:John)",
+			"AND(or(role:in:admin,user,age:lt:18),name:nnu:)",
+			"or(age:gt:30,secret:eql:1)",
+			"or()",
+			"or(age:gt:30",
+		}}.Encode()},
+	}
+	allowed := []string{"name", "age", "role"}
+
+	result := helper.GetFilters(req, allowed)
+	require.Equal(t, []helper.Filter{
+		{Operator: "or", Group: []helper.Filter{
+			{Field: "age", Operator: "gt", Value: "30"},
+			{Field: "name", Operator: "lik", Value: "John"},
+		}},
+		{Operator: "and", Group: []helper.Filter{
+			{Operator: "or", Group: []helper.Filter{
+				{Field: "role", Operator: "in", Value: "admin,user"},
+				{Field: "age", Operator: "lt", Value: "18"},
+			}},
+			{Field: "name", Operator: "nnu", Value: ""},
+		}},
+	}, result)
+	require.Equal(t, "and(or(role:in:admin,user,age:lt:18),name:nnu:)", result[1].String())
+}
+
+func TestParseFilters_Groups(t *testing.T) {
+	allowed := []string{"name", "age"}
+
+	filters, err := helper.ParseFilters([]string{"or(age:gt:30, name:eql:John)"}, helper.AllowColumns(allowed), nil)
+	require.NoError(t, err)
+	require.Len(t, filters[0].Group, 2)
+
+	for _, raw := range []string{"or(age:gt:30,secret:eql:1)", "or(age:drop:1)", "xor(age:gt:1)", "age:btw:1", "or(age:btw:1,2,3)"} {
+		_, err := helper.ParseFilters([]string{raw}, helper.AllowColumns(allowed), nil)
+		require.ErrorIs(t, err, helper.ErrInvalidFilter, raw)
+	}
+}
+
+func TestBuildWhereClause_Groups(t *testing.T) {
+	where, args := helper.BuildWhereClause([]helper.Filter{
+		{Field: "active", Operator: "eql", Value: "1"},
+		{Operator: "or", Group: []helper.Filter{
+			{Field: "age", Operator: "gt", Value: "30"},
+			{Operator: "and", Group: []helper.Filter{
+				{Field: "name", Operator: "stw", Value: "Jo"},
+				{Field: "age", Operator: "nbw", Value: "10,20"},
+			}},
+		}},
+	})
+	require.Equal(t, "WHERE `active` = ? AND (`age` > ? OR (`name` LIKE ? ESCAPE '!' AND `age` NOT BETWEEN ? AND ?))", where)
+	require.Equal(t, []interface{}{"1", "30", "Jo%", "10", "20"}, args)
+}
+
+func TestBuildWhereClause_NewOperators(t *testing.T) {
+	where, args := helper.BuildWhereClause([]helper.Filter{
+		{Field: "email", Operator: "ieq", Value: "Ann@Example.com"},
+		{Field: "name", Operator: "enw", Value: "son"},
+		{Field: "name", Operator: "nlk", Value: "100%_sure!"},
+		{Field: "role", Operator: "nin", Value: "admin, root"},
+	})
+	require.Equal(t, "WHERE LOWER(`email`) = LOWER(?) AND `name` LIKE ? ESCAPE '!' AND `name` NOT LIKE ? ESCAPE '!' AND `role` NOT IN (?,?)", where)
+	require.Equal(t, []interface{}{"Ann@Example.com", "%son", "%100!%!_sure!!%", "admin", "root"}, args)
+}
+
+func TestFilterParams_Typed(t *testing.T) {
+	schema := map[string]string{
+		"name":       "string",
+		"age":        "int",
+		"score":      "float64",
+		"active":     "bool",
+		"created_at": "*time.Time",
+	}
+	req := &http.Request{
+		URL: &url.URL{RawQuery: url.Values{"filter": {
+			"age:in:30, 31",
+			"or(active:eql:true,score:gte:2.5)",
+			"created_at:btw:2025-01-01,2025-01-31 23:59:59",
+			"name:lik:10",
+		}}.Encode()},
+	}
+
+	filters, problems := helper.FilterParams(req, helper.AllowColumns(helper.MapKeys(schema)), schema)
+	require.Empty(t, problems)
+	require.Len(t, filters, 4)
+
+	_, args := helper.BuildWhereClause(filters)
+	require.Equal(t, []interface{}{
+		int64(30), int64(31),
+		true, 2.5,
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+		"%10%",
+	}, args)
+}
+
+func TestFilterParams_Problems(t *testing.T) {
+	schema := map[string]string{"age": "int", "active": "bool", "created_at": "*time.Time"}
+	req := &http.Request{
+		URL: &url.URL{RawQuery: url.Values{"filter": {
+			"age:gt:abc",
+			"or(active:eql:yes,age:gt:1)",
+			"created_at:gte:yesterday",
+			"age:btw:1",
+			"secret:eql:1",
+			"age:drop:1",
+			"invalid",
+			"age:lt:9",
+		}}.Encode()},
+	}
+
+	filters, problems := helper.FilterParams(req, helper.AllowColumns(helper.MapKeys(schema)), schema)
+	require.Equal(t, []helper.Filter{{Field: "age", Operator: "lt", Value: "9", Type: "int"}}, filters)
+	require.Len(t, problems, 7)
+	require.Equal(t, helper.QueryProblem{Param: "filter", Value: "age:gt:abc", Message: `age: "abc" is not an integer`}, problems[0])
+	require.Contains(t, problems[1].Message, "not a boolean")
+	require.Contains(t, problems[2].Message, "not a date")
+	require.Contains(t, problems[3].Message, "needs two comma-separated values")
+	require.Contains(t, problems[4].Message, `unknown field "secret"`)
+	require.Contains(t, problems[5].Message, `unsupported operator "drop"`)
+	require.Equal(t, "malformed filter", problems[6].Message)
+
+	_, err := helper.ParseFilters([]string{"age:gt:abc"}, helper.AllowColumns(helper.MapKeys(schema)), schema)
+	require.ErrorIs(t, err, helper.ErrInvalidFilter)
+}
+
+func TestFilterRules_Defaults(t *testing.T) {
+	allowed := []string{"age"}
+
+	_, err := helper.ParseFilters([]string{"age:in:" + strings.Repeat("1,", helper.DefaultMaxInValues) + "1"}, helper.AllowColumns(allowed), nil)
+	require.ErrorIs(t, err, helper.ErrInvalidFilter)
+	require.Contains(t, err.Error(), "in takes at most 100 values")
+
+	raw := make([]string, helper.DefaultMaxFilters+1)
+	for i := range raw {
+		raw[i] = "age:gt:1"
+	}
+	_, err = helper.ParseFilters(raw, helper.AllowColumns(allowed), nil)
+	require.ErrorContains(t, err, "at most 10 filter conditions")
+
+	filters, err := helper.ParseFilters(raw[1:], helper.AllowColumns(allowed), nil)
+	require.NoError(t, err)
+	require.Len(t, filters, helper.DefaultMaxFilters)
+}
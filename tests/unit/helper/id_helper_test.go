@@ -0,0 +1,60 @@
+package helper
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDStrategy_GeneratedIDsValidate(t *testing.T) {
+	strategies := []helper.IDStrategy{
+		helper.ULIDStrategy{},
+		helper.UUIDv4Strategy{},
+		helper.UUIDv7Strategy{},
+	}
+	for _, s := range strategies {
+		id, err := s.NewID()
+		require.NoError(t, err)
+		require.NotEmpty(t, id)
+		require.NoError(t, s.ValidateID(id), id)
+	}
+}
+
+func TestUUIDStrategy_Format(t *testing.T) {
+	v4, err := helper.UUIDv4Strategy{}.NewID()
+	require.NoError(t, err)
+	require.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), v4)
+
+	first, err := helper.UUIDv7Strategy{}.NewID()
+	require.NoError(t, err)
+	require.Equal(t, byte('7'), first[14])
+	require.Error(t, helper.UUIDv4Strategy{}.ValidateID(first))
+	require.Error(t, helper.UUIDv7Strategy{}.ValidateID(v4))
+}
+
+func TestIDStrategy_RejectsMalformed(t *testing.T) {
+	require.ErrorIs(t, helper.ULIDStrategy{}.ValidateID("01ARZ3NDEKTSV4RRFFQ69G5FA"), helper.ErrInvalidID)
+	require.ErrorIs(t, helper.ULIDStrategy{}.ValidateID("01ARZ3NDEKTSV4RRFFQ69G5FAU"), helper.ErrInvalidID)
+	require.ErrorIs(t, helper.ULIDStrategy{}.ValidateID("81ARZ3NDEKTSV4RRFFQ69G5FAV"), helper.ErrInvalidID)
+	require.NoError(t, helper.ULIDStrategy{}.ValidateID("01ARZ3NDEKTSV4RRFFQ69G5FAV"))
+
+	require.ErrorIs(t, helper.UUIDv4Strategy{}.ValidateID("not-a-uuid"), helper.ErrInvalidID)
+	require.ErrorIs(t, helper.UUIDv4Strategy{}.ValidateID("zzzzzzzz-zzzz-4zzz-8zzz-zzzzzzzzzzzz"), helper.ErrInvalidID)
+
+	require.ErrorIs(t, helper.AutoIncrementStrategy{}.ValidateID("abc"), helper.ErrInvalidID)
+	require.ErrorIs(t, helper.AutoIncrementStrategy{}.ValidateID("0"), helper.ErrInvalidID)
+	require.NoError(t, helper.AutoIncrementStrategy{}.ValidateID("42"))
+}
+
+func TestIDStrategy_DatabaseAndClientIDs(t *testing.T) {
+	id, err := helper.AutoIncrementStrategy{}.NewID()
+	require.NoError(t, err)
+	require.Empty(t, id)
+
+	_, err = helper.ClientIDStrategy{}.NewID()
+	require.ErrorIs(t, err, helper.ErrIDRequired)
+	require.ErrorIs(t, helper.ClientIDStrategy{}.ValidateID(" "), helper.ErrIDRequired)
+	require.NoError(t, helper.ClientIDStrategy{}.ValidateID("sku-123"))
+}
@@ -40,3 +40,31 @@ func TestGetOrderParams_EmptyQuery(t *testing.T) {
 	require.Equal(t, "id", orderBy)
 	require.Equal(t, "DESC", orderDir)
 }
+
+func TestSortTerms(t *testing.T) {
+	require.Equal(t, []helper.SortKey{
+		{Column: "status", Order: "ASC"},
+		{Column: "created_at", Order: "DESC"},
+		{Column: "name", Order: ""},
+	}, helper.SortTerms(" status:asc, created_at:Desc ,,name"))
+	require.Empty(t, helper.SortTerms(""))
+}
+
+func TestParseSort(t *testing.T) {
+	allowed := []string{"id", "status", "created_at", "name"}
+
+	sort := helper.ParseSort("status:asc,created_at:desc", "DESC", allowed, []string{"id"})
+	require.Equal(t, "status:ASC,created_at:DESC,id:DESC", helper.SortString(sort))
+
+	sort = helper.ParseSort("secret,name:up,name:asc", "ASC", allowed, []string{"id"})
+	require.Equal(t, "name:ASC,id:ASC", helper.SortString(sort))
+
+	sort = helper.ParseSort("id:asc,name", "DESC", allowed, []string{"id"})
+	require.Equal(t, "id:ASC", helper.SortString(sort))
+
+	sort = helper.ParseSort("", "asc", allowed, []string{"id"})
+	require.Equal(t, "id:DESC", helper.SortString(sort))
+
+	sort = helper.ParseSort("group_id:asc", "DESC", []string{"user_id", "group_id"}, []string{"user_id", "group_id"})
+	require.Equal(t, "group_id:ASC,user_id:DESC", helper.SortString(sort))
+}
@@ -2,38 +2,106 @@ package helper
 
 import (
 	"encoding/base64"
-	"encoding/json"
-	"fmt"
 	"net/http/httptest"
-	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/not-empty/grit-microframework-go/app/helper"
 	"github.com/stretchr/testify/require"
 )
 
 func TestEncodeDecodeCursor_Roundtrip(t *testing.T) {
-	orig := helper.PageCursor{LastID: "abc123", LastValue: "xyz789"}
-	token := helper.EncodeCursor(orig)
+	orig := helper.PageCursor{
+		Values:  []any{nil, int64(9007199254740993), "admins"},
+		OrderBy: "role:DESC,user_id:DESC,group_id:DESC",
+		Filters: helper.FiltersDigest([]helper.Filter{{Field: "age", Operator: "gt", Value: "18"}}),
+	}
+
+	decoded, err := helper.DecodeCursor(helper.EncodeCursor(orig))
+	require.NoError(t, err)
+	require.Equal(t, orig, decoded)
+}
+
+func TestDecodeCursor_TypedValues(t *testing.T) {
+	orig := helper.PageCursor{Values: []any{2.5, true, "a"}, OrderBy: "score:ASC,active:ASC,id:ASC"}
+	decoded, err := helper.DecodeCursor(helper.EncodeCursor(orig))
+	require.NoError(t, err)
+	require.Equal(t, []any{2.5, true, "a"}, decoded.Values)
+}
+
+func TestPageCursor_TimeKeepsFullPrecision(t *testing.T) {
+	at := time.Date(2025, 6, 6, 10, 0, 0, 150_000_000, time.FixedZone("BRT", -3*3600))
+	row := map[string]any{"created_at": helper.JSONTime(at), "id": "01"}
+	sort := []helper.SortKey{{Column: "created_at", Order: "DESC"}, {Column: "id", Order: "DESC"}}
 
-	decoded, err := helper.DecodeCursor(token)
+	decoded, err := helper.DecodeCursor(helper.EncodeCursor(helper.NewPageCursor(row, sort, nil, false)))
 	require.NoError(t, err)
-	require.Equal(t, orig.LastID, decoded.LastID)
-	require.Equal(t, orig.LastValue, decoded.LastValue)
+	require.Len(t, decoded.Values, 2)
+	require.True(t, at.Equal(decoded.Values[0].(time.Time)))
+	require.Equal(t, "01", decoded.Values[1])
 }
 
-func TestDecodeCursor_InvalidEncoding(t *testing.T) {
-	_, err := helper.DecodeCursor("!!!not-base64$$$")
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "invalid cursor encoding")
+func TestDecodeCursor_BadTimeValue(t *testing.T) {
+	token := helper.EncodeCursor(helper.PageCursor{Values: []any{"yesterday"}, Times: []int{0}, OrderBy: "created_at:ASC"})
+	_, err := helper.DecodeCursor(token)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+
+	token = helper.EncodeCursor(helper.PageCursor{Values: []any{"a"}, Times: []int{3}, OrderBy: "id:ASC"})
+	_, err = helper.DecodeCursor(token)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+}
+
+func TestDecodeCursor_Rejected(t *testing.T) {
+	token := helper.EncodeCursor(helper.PageCursor{Values: []any{"a"}, OrderBy: "id:ASC"})
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString([]byte(`{"v":["z"],"o":"id:ASC"}`))
+
+	for _, bad := range []string{
+		"",
+		payload,
+		forged + "." + sig,
+		payload + ".!!!",
+		payload + "." + sig + "x",
+	} {
+		_, err := helper.DecodeCursor(bad)
+		require.ErrorIs(t, err, helper.ErrInvalidCursor, bad)
+	}
 }
 
-func TestDecodeCursor_InvalidPayload(t *testing.T) {
-	bad := base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString([]byte("not-json"))
-	_, err := helper.DecodeCursor(bad)
-	require.Error(t, err)
-	require.Contains(t, err.Error(), "invalid cursor payload")
+func TestDecodeCursor_OtherSecret(t *testing.T) {
+	helper.SetCursorSecret("first")
+	token := helper.EncodeCursor(helper.PageCursor{Values: []any{"a"}, OrderBy: "id:ASC"})
+
+	helper.SetCursorSecret("second")
+	_, err := helper.DecodeCursor(token)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+
+	helper.SetCursorSecret("")
+	_, err = helper.DecodeCursor(helper.EncodeCursor(helper.PageCursor{Values: []any{"a"}}))
+	require.NoError(t, err)
+}
+
+func TestPageCursor_Matches(t *testing.T) {
+	filters := []helper.Filter{
+		{Field: "age", Operator: "gt", Value: "18"},
+		{Field: "name", Operator: "lik", Value: "an"},
+	}
+	sort := []helper.SortKey{{Column: "age", Order: "ASC"}, {Column: "id", Order: "ASC"}}
+	c := helper.NewPageCursor(map[string]any{"id": "a", "age": 20}, sort, filters, false)
+	require.Equal(t, []any{20, "a"}, c.Values)
+	require.Equal(t, "age:ASC,id:ASC", c.OrderBy)
+
+	reordered := []helper.Filter{filters[1], filters[0]}
+	require.NoError(t, c.Matches(sort, reordered))
+	require.ErrorIs(t, c.Matches([]helper.SortKey{{Column: "age", Order: "DESC"}, {Column: "id", Order: "ASC"}}, filters), helper.ErrInvalidCursor)
+	require.ErrorIs(t, c.Matches(sort[1:], filters), helper.ErrInvalidCursor)
+	require.ErrorIs(t, c.Matches(sort, filters[:1]), helper.ErrInvalidCursor)
+
+	c.Values = c.Values[:1]
+	require.ErrorIs(t, c.Matches(sort, filters), helper.ErrInvalidCursor)
+	require.Empty(t, helper.FiltersDigest(nil))
 }
 
 func TestGetPaginationParams_NoCursor(t *testing.T) {
@@ -45,7 +113,7 @@ func TestGetPaginationParams_NoCursor(t *testing.T) {
 }
 
 func TestGetPaginationParams_ValidCursor(t *testing.T) {
-	orig := helper.PageCursor{LastID: "id42", LastValue: "val42"}
+	orig := helper.PageCursor{Values: []any{"val42", "id42"}, OrderBy: "name:ASC,id:ASC"}
 	tok := helper.EncodeCursor(orig)
 
 	req := httptest.NewRequest("GET", "/?page_cursor="+tok, nil)
@@ -53,99 +121,64 @@ func TestGetPaginationParams_ValidCursor(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, helper.DefaultPageLimit, limit)
 	require.NotNil(t, cursor)
-	require.Equal(t, orig.LastID, cursor.LastID)
-	require.Equal(t, orig.LastValue, cursor.LastValue)
+	require.Equal(t, orig, *cursor)
 }
 
-func TestGetPaginationParams_InvalidCursorEncoding(t *testing.T) {
-	req := httptest.NewRequest("GET", "/?page_cursor=not-base64!", nil)
-
-	limit, cursor, err := helper.GetPaginationParams(req)
-
-	require.Error(t, err)
-	require.EqualError(t, err, "Invalid cursor encoding")
-
-	require.Nil(t, cursor)
-	require.Equal(t, helper.DefaultPageLimit, limit)
-}
-
-func TestGetPaginationParams_InvalidCursorPayload(t *testing.T) {
-	badJSON := base64.URLEncoding.WithPadding(base64.NoPadding).
-		EncodeToString([]byte("not-json"))
-
-	req := httptest.NewRequest("GET", "/?page_cursor="+badJSON, nil)
-
-	limit, cursor, err := helper.GetPaginationParams(req)
-
-	require.Error(t, err)
-	require.EqualError(t, err, "Invalid cursor payload")
-	require.Nil(t, cursor)
-	require.Equal(t, helper.DefaultPageLimit, limit)
-}
+func TestGetPaginationParams_InvalidCursor(t *testing.T) {
+	unsigned := base64.URLEncoding.WithPadding(base64.NoPadding).
+		EncodeToString([]byte(`{"last_id":"1","last_value":"1"}`))
 
-func TestBuildPageCursor_NonArrayAndInvalidJSON(t *testing.T) {
-	c, err := helper.BuildPageCursor([]byte(`{"foo":1}`), url.Values{})
-	require.NoError(t, err)
-	require.Empty(t, c)
+	for _, raw := range []string{"not-base64!", unsigned} {
+		req := httptest.NewRequest("GET", "/?page_cursor="+raw, nil)
 
-	c, err = helper.BuildPageCursor([]byte(`[1,2`), url.Values{})
-	require.NoError(t, err)
-	require.Empty(t, c)
-}
+		limit, cursor, err := helper.GetPaginationParams(req)
 
-func TestBuildPageCursor_ShorterThanLimit(t *testing.T) {
-	arr := make([]map[string]interface{}, helper.DefaultPageLimit-1)
-	for i := range arr {
-		arr[i] = map[string]interface{}{"id": fmt.Sprintf("id%d", i+1), "score": float64(i * 10)}
+		require.ErrorIs(t, err, helper.ErrInvalidCursor)
+		require.Nil(t, cursor)
+		require.Equal(t, helper.DefaultPageLimit, limit)
 	}
-	body, err := json.Marshal(arr)
-	require.NoError(t, err)
-
-	cursor, err := helper.BuildPageCursor(body, url.Values{"order_by": []string{"score"}})
-	require.NoError(t, err)
-	require.Empty(t, cursor)
 }
 
-func TestBuildPageCursor_FullPage_DefaultOrderBy(t *testing.T) {
-	arr := make([]map[string]interface{}, helper.DefaultPageLimit)
-	for i := range arr {
-		arr[i] = map[string]interface{}{
-			"id":    fmt.Sprintf("id%d", i+1),
-			"score": float64((i + 1) * 100),
-		}
+func TestPageCursors(t *testing.T) {
+	rows := []map[string]any{
+		{"user_id": 7, "group_id": "admins", "role": "owner"},
+		{"user_id": 8, "group_id": "users", "role": nil},
 	}
-	body, err := json.Marshal(arr)
-	require.NoError(t, err)
-
-	cursor, err := helper.BuildPageCursor(body, url.Values{})
-	require.NoError(t, err)
-	require.NotEmpty(t, cursor)
-
-	pc, err := helper.DecodeCursor(cursor)
-	require.NoError(t, err)
-	require.Equal(t, "id25", pc.LastID)
-	require.Equal(t, "id25", pc.LastValue)
-}
-
-func TestBuildPageCursor_FullPage_CustomOrderBy(t *testing.T) {
-	arr := make([]map[string]interface{}, helper.DefaultPageLimit)
-	for i := range arr {
-		arr[i] = map[string]interface{}{
-			"id":    fmt.Sprintf("id%d", i+1),
-			"score": float64((i + 1) * 7),
-		}
+	sort := helper.ParseSort("role", "ASC", []string{"role", "user_id", "group_id"}, []string{"user_id", "group_id"})
+	decode := func(token string) helper.PageCursor {
+		c, err := helper.DecodeCursor(token)
+		require.NoError(t, err)
+		return c
 	}
-	body, err := json.Marshal(arr)
-	require.NoError(t, err)
 
-	query := url.Values{"order_by": []string{"score"}}
-	cursor, err := helper.BuildPageCursor(body, query)
-	require.NoError(t, err)
-
-	pc, err := helper.DecodeCursor(cursor)
-	require.NoError(t, err)
-	require.Equal(t, "id25", pc.LastID)
-	require.Equal(t, "175", pc.LastValue)
+	next, prev := helper.PageCursors(rows, 2, nil, sort, nil)
+	require.Empty(t, prev)
+	c := decode(next)
+	require.Equal(t, []any{nil, int64(8), "users"}, c.Values)
+	require.Equal(t, "role:ASC,user_id:ASC,group_id:ASC", c.OrderBy)
+	require.False(t, c.Backward)
+
+	next, prev = helper.PageCursors(rows, 3, nil, sort, nil)
+	require.Empty(t, next)
+	require.Empty(t, prev)
+
+	next, prev = helper.PageCursors(rows, 3, &c, sort, nil)
+	require.Empty(t, next)
+	p := decode(prev)
+	require.True(t, p.Backward)
+	require.Equal(t, []any{"owner", int64(7), "admins"}, p.Values)
+
+	next, prev = helper.PageCursors(rows, 3, &p, sort, nil)
+	require.NotEmpty(t, next)
+	require.Empty(t, prev)
+
+	next, prev = helper.PageCursors(rows, 2, &p, sort, nil)
+	require.NotEmpty(t, next)
+	require.NotEmpty(t, prev)
+
+	next, prev = helper.PageCursors(nil, 2, &c, sort, nil)
+	require.Empty(t, next)
+	require.Empty(t, prev)
 }
 
 func TestParseLimit_CustomWithinBounds(t *testing.T) {
@@ -164,3 +197,44 @@ func TestParseLimit_FallbackToDefault(t *testing.T) {
 		require.Equal(t, def, got, "ParseLimit(%q) should fallback", raw)
 	}
 }
+
+func TestGetOffsetParams(t *testing.T) {
+	cases := map[string]int{
+		"/x":          0,
+		"/x?page=1":   0,
+		"/x?page=3":   20,
+		"/x?offset=7": 7,
+	}
+	for path, want := range cases {
+		offset, err := helper.GetOffsetParams(httptest.NewRequest("GET", path, nil), 10, 100, nil)
+		require.NoError(t, err, path)
+		require.Equal(t, want, offset, path)
+	}
+}
+
+func TestGetOffsetParams_Errors(t *testing.T) {
+	cases := map[string]error{
+		"/x?page=0":     helper.ErrInvalidPage,
+		"/x?page=abc":   helper.ErrInvalidPage,
+		"/x?offset=-1":  helper.ErrInvalidPage,
+		"/x?page=12":    helper.ErrOffsetTooLarge,
+		"/x?offset=101": helper.ErrOffsetTooLarge,
+	}
+	for path, want := range cases {
+		_, err := helper.GetOffsetParams(httptest.NewRequest("GET", path, nil), 10, 100, nil)
+		require.ErrorIs(t, err, want, path)
+	}
+
+	_, err := helper.GetOffsetParams(httptest.NewRequest("GET", "/x?page=2", nil), 10, 100, &helper.PageCursor{Values: []any{"a"}})
+	require.ErrorIs(t, err, helper.ErrPageWithCursor)
+
+	_, err = helper.GetOffsetParams(httptest.NewRequest("GET", "/x?offset=10001", nil), 10, 0, nil)
+	require.ErrorIs(t, err, helper.ErrOffsetTooLarge)
+}
+
+func TestWantsTotal(t *testing.T) {
+	require.True(t, helper.WantsTotal(httptest.NewRequest("GET", "/x?with_total=true", nil)))
+	require.True(t, helper.WantsTotal(httptest.NewRequest("GET", "/x?with_total=1", nil)))
+	require.False(t, helper.WantsTotal(httptest.NewRequest("GET", "/x?with_total=no", nil)))
+	require.False(t, helper.WantsTotal(httptest.NewRequest("GET", "/x", nil)))
+}
@@ -0,0 +1,27 @@
+package helper
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefersRepresentation(t *testing.T) {
+	cases := map[string]bool{
+		"":                                       false,
+		"return=minimal":                         false,
+		"return=representation":                  true,
+		`respond-async, Return="Representation"`: true,
+		"return=representation; charset=utf-8":   true,
+		"handling=lenient":                       false,
+	}
+	for header, want := range cases {
+		req := httptest.NewRequest("POST", "/example/add", nil)
+		if header != "" {
+			req.Header.Set("Prefer", header)
+		}
+		require.Equal(t, want, helper.PrefersRepresentation(req), header)
+	}
+}
@@ -0,0 +1,89 @@
+package helper
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckQueryParams(t *testing.T) {
+	schema := map[string]string{"id": "string", "name": "string", "age": "int"}
+	allowed := []string{"id", "name", "age"}
+	rules := helper.QueryRules{Fields: allowed, Sortable: allowed, Filters: helper.AllowColumns(allowed)}
+
+	req := httptest.NewRequest("GET", "/x?order_by=name&order=asc&limit=10&fields=id,name&filter=age:gt:3", nil)
+	require.Empty(t, helper.CheckQueryParams(req, rules, schema))
+
+	req = httptest.NewRequest("GET", "/x?order_by=salary&order=up&limit=500&fields=id,salary&filter=age:gt:old", nil)
+	problems := helper.CheckQueryParams(req, rules, schema)
+	require.Equal(t, []helper.QueryProblem{
+		{Param: "order_by", Value: "salary", Message: `unknown field "salary"`},
+		{Param: "order", Value: "up", Message: "must be asc or desc"},
+		{Param: "limit", Value: "500", Message: "must be an integer from 1 to 25"},
+		{Param: "fields", Value: "id,salary", Message: `unknown field "salary"`},
+		{Param: "filter", Value: "age:gt:old", Message: `age: "old" is not an integer`},
+	}, problems)
+}
+
+func TestCheckQueryParams_SortTerms(t *testing.T) {
+	schema := map[string]string{"id": "string", "name": "string", "age": "int"}
+	allowed := []string{"id", "name", "age"}
+	rules := helper.QueryRules{Fields: allowed, Sortable: []string{"id", "name"}, Filters: helper.AllowColumns(allowed)}
+
+	req := httptest.NewRequest("GET", "/x?order_by=name:asc,id:desc", nil)
+	require.Empty(t, helper.CheckQueryParams(req, rules, schema))
+
+	req = httptest.NewRequest("GET", "/x?order_by=name:up,age:asc,salary", nil)
+	require.Equal(t, []helper.QueryProblem{
+		{Param: "order_by", Value: "name:up,age:asc,salary", Message: `direction of "name" must be asc or desc`},
+		{Param: "order_by", Value: "name:up,age:asc,salary", Message: `field "age" is not sortable`},
+		{Param: "order_by", Value: "name:up,age:asc,salary", Message: `unknown field "salary"`},
+	}, helper.CheckQueryParams(req, rules, schema))
+
+	req = httptest.NewRequest("GET", "/x?order_by=", nil)
+	require.Equal(t, "must name at least one field", helper.CheckQueryParams(req, rules, schema)[0].Message)
+}
+
+func TestCheckQueryLimits(t *testing.T) {
+	schema := map[string]string{"id": "string", "name": "string", "bio": "string", "age": "int"}
+	rules := helper.QueryRules{
+		Fields:   []string{"id", "name", "bio", "age"},
+		Sortable: []string{"id", "age"},
+		Filters: helper.FilterRules{
+			Columns:     map[string][]string{"id": nil, "age": {"eql", "in"}},
+			MaxFilters:  3,
+			MaxInValues: 2,
+		},
+	}
+
+	req := httptest.NewRequest("GET", "/x?order_by=age&filter=age:in:1,2&filter=nope:eql:1&order=up", nil)
+	require.Empty(t, helper.CheckQueryLimits(req, rules, schema))
+
+	req = httptest.NewRequest("GET", "/x?order_by=bio&filter=bio:lik:x&filter=age:gt:1&filter=age:in:1,2,3&filter=or(id:eql:1,id:eql:2)", nil)
+	require.Equal(t, []helper.QueryProblem{
+		{Param: "order_by", Value: "bio", Message: `field "bio" is not sortable`},
+		{Param: "filter", Value: "bio:lik:x", Message: `filter not allowed: field "bio" is not filterable`},
+		{Param: "filter", Value: "age:gt:1", Message: `filter not allowed: operator "gt" is not allowed on "age"`},
+		{Param: "filter", Value: "age:in:1,2,3", Message: "filter not allowed: in takes at most 2 values, got 3"},
+		{Param: "filter", Message: "filter not allowed: at most 3 filter conditions are allowed, got 5"},
+	}, helper.CheckQueryLimits(req, rules, schema))
+
+	problems := helper.CheckQueryParams(req, rules, schema)
+	require.Len(t, problems, 5)
+}
+
+func TestJSONQueryProblems(t *testing.T) {
+	rr := httptest.NewRecorder()
+	helper.JSONQueryProblems(rr, []helper.QueryProblem{{Param: "limit", Value: "0", Message: "must be an integer from 1 to 25"}})
+
+	require.Equal(t, 400, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body helper.QueryErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Equal(t, "Invalid query", body.Error)
+	require.Len(t, body.Problems, 1)
+}
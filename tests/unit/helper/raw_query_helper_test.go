@@ -99,3 +99,21 @@ func TestPrepareRawQuery(t *testing.T) {
 	require.Equal(t, expSQL3, sql3)
 	require.Equal(t, []interface{}{3, 3}, args3)
 }
+
+func TestPrepareRawQuery_KeepsTypeCasts(t *testing.T) {
+	q := "SELECT created_at::date AS day FROM tbl WHERE age > :age::int"
+	require.Equal(t, []string{"age"}, helper.ExtractRawParams(q))
+
+	sql, args := helper.PrepareRawQuery(q, map[string]any{"age": 18})
+	require.Equal(t, "SELECT created_at::date AS day FROM tbl WHERE age > ?::int LIMIT 25", sql)
+	require.Equal(t, []interface{}{18}, args)
+}
+
+func TestPrepareRawQuery_Postgres(t *testing.T) {
+	helper.SetDialect(helper.PostgresDialect{})
+	defer helper.SetDialect(helper.MySQLDialect{})
+
+	sql, args := helper.PrepareRawQuery("SELECT * FROM tbl WHERE a=:a AND b=:b", map[string]any{"a": 1, "b": 2})
+	require.Equal(t, "SELECT * FROM tbl WHERE a=$1 AND b=$2 LIMIT 25", sql)
+	require.Equal(t, []interface{}{1, 2}, args)
+}
@@ -69,3 +69,68 @@ func TestSanitizeModel_NoSanitize(t *testing.T) {
 	data := map[string]string{"key": "value"}
 	helper.SanitizeModel(data)
 }
+
+func TestParseKey(t *testing.T) {
+	schema := map[string]string{"id": "int", "user_id": "int", "group_id": "string"}
+
+	key, err := helper.ParseKey("42", []string{"id"}, schema)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(42)}, key)
+
+	key, err = helper.ParseKey("7/admins", []string{"user_id", "group_id"}, schema)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(7), "admins"}, key)
+
+	key, err = helper.ParseKey("01H/X", []string{"group_id"}, schema)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"01H/X"}, key)
+}
+
+func TestParseKey_IntegerTypes(t *testing.T) {
+	cases := map[string]interface{}{
+		"int":     int64(42),
+		"*int":    int64(42),
+		"int64":   int64(42),
+		"*int64":  int64(42),
+		"uint":    uint64(42),
+		"*uint":   uint64(42),
+		"uint64":  uint64(42),
+		"*uint64": uint64(42),
+	}
+	for typ, want := range cases {
+		t.Run(typ, func(t *testing.T) {
+			schema := map[string]string{"id": typ}
+
+			key, err := helper.ParseKey("42", []string{"id"}, schema)
+			require.NoError(t, err)
+			require.Equal(t, []interface{}{want}, key)
+
+			_, err = helper.ParseKey("abc", []string{"id"}, schema)
+			require.EqualError(t, err, "Invalid id")
+		})
+	}
+}
+
+func TestParseKey_UnsignedRejectsNegative(t *testing.T) {
+	_, err := helper.ParseKey("-1", []string{"id"}, map[string]string{"id": "uint64"})
+	require.EqualError(t, err, "Invalid id")
+}
+
+func TestParseKey_Errors(t *testing.T) {
+	schema := map[string]string{"id": "int", "user_id": "int", "group_id": "string"}
+
+	_, err := helper.ParseKey("abc", []string{"id"}, schema)
+	require.EqualError(t, err, "Invalid id")
+
+	_, err = helper.ParseKey("7", []string{"user_id", "group_id"}, schema)
+	require.EqualError(t, err, "ID must have 2 parts")
+
+	_, err = helper.ParseKey("7/", []string{"user_id", "group_id"}, schema)
+	require.EqualError(t, err, "Missing ID")
+}
+
+func TestKeyString(t *testing.T) {
+	require.Equal(t, "abc", helper.KeyString("abc"))
+	require.Equal(t, "42", helper.KeyString(json.Number("42")))
+	require.Equal(t, "7/admins", helper.KeyString([]interface{}{json.Number("7"), "admins"}))
+}
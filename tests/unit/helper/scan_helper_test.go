@@ -2,6 +2,7 @@ package helper
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
@@ -98,7 +99,8 @@ func TestGenericScanToMap_DefaultAssignmentNil(t *testing.T) {
 	schema := map[string]string{"id": "string"}
 	result, err := helper.GenericScanToMap(r, schema)
 	require.NoError(t, err)
-	require.Equal(t, "", result["id"])
+	require.Contains(t, result, "id")
+	require.Nil(t, result["id"])
 }
 
 func TestGenericScanToMap_TimeFormatOutputNullable(t *testing.T) {
@@ -120,7 +122,11 @@ func TestGenericScanToMap_TimeFormatOutputNullable(t *testing.T) {
 	schema := map[string]string{"created_at": "*time.Time"}
 	result, err := helper.GenericScanToMap(r, schema)
 	require.NoError(t, err)
-	require.Equal(t, "2023-10-02 15:04:05", result["created_at"])
+	require.Equal(t, helper.JSONTime(dt), result["created_at"])
+
+	out, err := json.Marshal(result["created_at"])
+	require.NoError(t, err)
+	require.JSONEq(t, `"2023-10-02 15:04:05"`, string(out))
 }
 
 func TestGenericScanToMap_TimeFormatOutput(t *testing.T) {
@@ -142,7 +148,11 @@ func TestGenericScanToMap_TimeFormatOutput(t *testing.T) {
 	schema := map[string]string{"created_at": "time.Time"}
 	result, err := helper.GenericScanToMap(r, schema)
 	require.NoError(t, err)
-	require.Equal(t, "2023-10-02 15:04:05", result["created_at"])
+	require.Equal(t, helper.JSONTime(dt), result["created_at"])
+
+	out, err := json.Marshal(result["created_at"])
+	require.NoError(t, err)
+	require.JSONEq(t, `"2023-10-02 15:04:05"`, string(out))
 }
 
 func TestGenericScanToMap_IntColumn(t *testing.T) {
@@ -172,12 +182,12 @@ func TestGenericScanToMap_UnsupportedTypeDefaultsToDiscard(t *testing.T) {
 	require.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"active"}).AddRow(true)
+	rows := sqlmock.NewRows([]string{"area"}).AddRow("POINT(1 2)")
 
 	mock.ExpectQuery("SELECT \\* FROM test").WillReturnRows(rows)
 
 	schema := map[string]string{
-		"active": "bool",
+		"area": "geometry",
 	}
 
 	r, err := db.Query("SELECT * FROM test")
@@ -188,7 +198,7 @@ func TestGenericScanToMap_UnsupportedTypeDefaultsToDiscard(t *testing.T) {
 
 	result, err := helper.GenericScanToMap(r, schema)
 	require.NoError(t, err)
-	require.NotContains(t, result, "active")
+	require.NotContains(t, result, "area")
 }
 
 func TestGenericScanToMap_NullStringValid(t *testing.T) {
@@ -242,7 +252,7 @@ func TestGenericScanToMap_NullInt64Invalid(t *testing.T) {
 	require.NoError(t, err)
 
 	require.Contains(t, result, "age")
-	require.Equal(t, 0, result["age"])
+	require.Nil(t, result["age"])
 }
 
 func TestGenericScanToMap_NullTimeInvalid(t *testing.T) {
@@ -500,3 +510,125 @@ func TestGenericScanToMap_DateColumnFormatsAsYYYYMMDD(t *testing.T) {
 
 	require.Equal(t, "2025-06-06", m["d"])
 }
+
+func TestGenericScanToMap_TypedColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"price", "ratio", "active", "meta", "avatar", "views", "note"}).
+		AddRow([]byte("19.90"), 0.25, int64(1), []byte(`{"a":1}`), []byte{0xde, 0xad}, []byte("18446744073709551615"), "")
+	mock.ExpectQuery("SELECT typed").WillReturnRows(rows)
+
+	r, err := db.Query("SELECT typed")
+	require.NoError(t, err)
+	defer r.Close()
+	require.True(t, r.Next())
+
+	schema := map[string]string{
+		"price":  "decimal",
+		"ratio":  "float64",
+		"active": "bool",
+		"meta":   "json.RawMessage",
+		"avatar": "[]byte",
+		"views":  "uint64",
+		"note":   "*string",
+	}
+	result, err := helper.GenericScanToMap(r, schema)
+	require.NoError(t, err)
+
+	require.Equal(t, "19.90", result["price"])
+	require.Equal(t, 0.25, result["ratio"])
+	require.Equal(t, true, result["active"])
+	require.Equal(t, json.RawMessage(`{"a":1}`), result["meta"])
+	require.Equal(t, []byte{0xde, 0xad}, result["avatar"])
+	require.Equal(t, uint64(18446744073709551615), result["views"])
+	require.Equal(t, "", result["note"])
+
+	out, err := json.Marshal(result)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"price":"19.90","ratio":0.25,"active":true,"meta":{"a":1},"avatar":"3q0=","views":18446744073709551615,"note":""}`, string(out))
+}
+
+func TestGenericScanToMap_TypedColumnsNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"price", "ratio", "active", "meta", "avatar", "views"}).
+		AddRow(nil, nil, nil, nil, nil, nil)
+	mock.ExpectQuery("SELECT typed").WillReturnRows(rows)
+
+	r, err := db.Query("SELECT typed")
+	require.NoError(t, err)
+	defer r.Close()
+	require.True(t, r.Next())
+
+	schema := map[string]string{
+		"price":  "decimal",
+		"ratio":  "float64",
+		"active": "bool",
+		"meta":   "json",
+		"avatar": "[]byte",
+		"views":  "uint64",
+	}
+	result, err := helper.GenericScanToMap(r, schema)
+	require.NoError(t, err)
+
+	out, err := json.Marshal(result)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"price":null,"ratio":null,"active":null,"meta":null,"avatar":null,"views":null}`, string(out))
+}
+
+func TestGenericScanToMap_InvalidJSONFallsBackToString(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT meta").WillReturnRows(sqlmock.NewRows([]string{"meta"}).AddRow([]byte("not json")))
+
+	r, err := db.Query("SELECT meta")
+	require.NoError(t, err)
+	defer r.Close()
+	require.True(t, r.Next())
+
+	result, err := helper.GenericScanToMap(r, map[string]string{"meta": "json"})
+	require.NoError(t, err)
+	require.Equal(t, "not json", result["meta"])
+}
+
+func TestSchemaScanRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRowsWithColumnDefinition(
+		sqlmock.NewColumn("name").OfType("VARCHAR", ""),
+		sqlmock.NewColumn("total").OfType("DECIMAL", ""),
+		sqlmock.NewColumn("label").OfType("VARCHAR", ""),
+	).
+		AddRow(nil, []byte("10.50"), []byte("x")).
+		AddRow("ana", []byte("2.00"), nil)
+	mock.ExpectQuery("SELECT raw").WillReturnRows(rows)
+
+	r, err := db.Query("SELECT raw")
+	require.NoError(t, err)
+	defer r.Close()
+
+	out, err := helper.SchemaScanRows(r, map[string]string{"name": "string"})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{
+		{"name": nil, "total": "10.50", "label": "x"},
+		{"name": "ana", "total": "2.00", "label": nil},
+	}, out)
+}
+
+func TestColumnSchemaType(t *testing.T) {
+	require.Equal(t, "json", helper.ColumnSchemaType("json"))
+	require.Equal(t, "decimal", helper.ColumnSchemaType("NUMERIC"))
+	require.Equal(t, "float64", helper.ColumnSchemaType("DOUBLE"))
+	require.Equal(t, "bool", helper.ColumnSchemaType("BOOLEAN"))
+	require.Equal(t, "uint64", helper.ColumnSchemaType("UNSIGNED BIGINT"))
+	require.Equal(t, "[]byte", helper.ColumnSchemaType("BYTEA"))
+	require.Equal(t, "", helper.ColumnSchemaType("VARCHAR"))
+}
@@ -0,0 +1,42 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchFilter_MySQL(t *testing.T) {
+	search := helper.SearchFilter([]string{"title", "body"}, " go 100% ")
+	require.Equal(t, helper.Filter{Field: "title,body", Operator: "search", Value: "go 100%"}, search)
+
+	filters := []helper.Filter{{Field: "age", Operator: "gt", Value: "3"}, search}
+	found, ok := helper.FindSearch(filters)
+	require.True(t, ok)
+	require.Equal(t, search, found)
+	_, ok = helper.FindSearch(filters[:1])
+	require.False(t, ok)
+
+	clause, args := helper.BuildWhereClause(filters)
+	require.Equal(t, "WHERE `age` > ? AND MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)", clause)
+	require.Equal(t, []interface{}{"3", "go 100%"}, args)
+
+	expr, args := helper.RelevanceExpression(helper.MySQLDialect{}, search)
+	require.Equal(t, "MATCH (`title`, `body`) AGAINST (? IN NATURAL LANGUAGE MODE)", expr)
+	require.Equal(t, []interface{}{"go 100%"}, args)
+}
+
+func TestSearchFilter_LikeFallback(t *testing.T) {
+	helper.SetDialect(helper.SQLiteDialect{})
+	t.Cleanup(func() { helper.SetDialect(nil) })
+
+	search := helper.SearchFilter([]string{"title", "body"}, "Go_")
+	clause, args := helper.BuildWhereClause([]helper.Filter{search})
+	require.Equal(t, `WHERE (LOWER("title") LIKE ? ESCAPE '!' OR LOWER("body") LIKE ? ESCAPE '!')`, clause)
+	require.Equal(t, []interface{}{"%go!_%", "%go!_%"}, args)
+
+	expr, args := helper.RelevanceExpression(helper.SQLiteDialect{}, search)
+	require.Equal(t, `(CASE WHEN LOWER("title") LIKE ? ESCAPE '!' THEN 1 ELSE 0 END + CASE WHEN LOWER("body") LIKE ? ESCAPE '!' THEN 1 ELSE 0 END)`, expr)
+	require.Equal(t, []interface{}{"%go!_%", "%go!_%"}, args)
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -93,47 +92,22 @@ func TestResponseMiddleware_CallsHeaderMethod(t *testing.T) {
 	require.Equal(t, http.StatusAccepted, rr.Code)
 }
 
-func TestResponseMiddleware_SetsPageCursorHeader(t *testing.T) {
-	const limit = helper.DefaultPageLimit
-	arr := make([]map[string]interface{}, limit)
-	for i := 0; i < limit; i++ {
-		arr[i] = map[string]interface{}{
-			"id":    fmt.Sprintf("%d", i+1),
-			"value": fmt.Sprintf("value%d", i+1),
-		}
+func TestResponseMiddleware_LeavesPageCursorToHandler(t *testing.T) {
+	arr := make([]map[string]interface{}, helper.DefaultPageLimit)
+	for i := range arr {
+		arr[i] = map[string]interface{}{"id": fmt.Sprintf("%d", i+1)}
 	}
 	bodyBytes, err := json.Marshal(arr)
 	require.NoError(t, err)
 
-	req := httptest.NewRequest("GET", "/foo", bytes.NewReader(bodyBytes))
-	rr := httptest.NewRecorder()
-
 	handler := middleware.ResponseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write(bodyBytes)
 	}))
 
-	handler.ServeHTTP(rr, req)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/foo", nil))
 	require.Equal(t, http.StatusOK, rr.Code)
-
-	cursor := rr.Header().Get("X-Page-Cursor")
-	require.NotEmpty(t, cursor, "expected X-Page-Cursor header")
-
-	pc, err := helper.DecodeCursor(cursor)
-	require.NoError(t, err)
-	require.Equal(t, "25", pc.LastID)
-	require.Equal(t, "25", pc.LastValue)
-
-	req2 := httptest.NewRequest("GET", "/foo?order_by=value", bytes.NewReader(bodyBytes))
-	rr2 := httptest.NewRecorder()
-	handler.ServeHTTP(rr2, req2)
-	require.Equal(t, http.StatusOK, rr2.Code)
-
-	cursor2 := rr2.Header().Get("X-Page-Cursor")
-	require.NotEmpty(t, cursor2)
-
-	pc2, err := helper.DecodeCursor(cursor2)
-	require.NoError(t, err)
-	require.Equal(t, "25", pc2.LastID)
-	require.Equal(t, "value25", pc2.LastValue)
+	require.Empty(t, rr.Header().Get("X-Page-Cursor"))
+	require.JSONEq(t, string(bodyBytes), rr.Body.String())
 }
@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate_GroupBy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `role`, COUNT(*) AS `count`, MAX(`user_id`) AS `max_user_id` FROM `membership` "+
+			"WHERE `role` != ? AND `deleted_at` IS NULL GROUP BY `role` ORDER BY `role` LIMIT ?",
+	)).
+		WithArgs("guest", 100).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "count", "max_user_id"}).
+			AddRow("member", []byte("3"), int64(9)).
+			AddRow("owner", []byte("1"), int64(7)))
+
+	rows, err := newMembershipRepo(db).Aggregate(context.Background(),
+		[]helper.Aggregate{{Func: "count"}, {Func: "max", Field: "user_id"}},
+		[]string{"role"},
+		[]helper.Filter{{Field: "role", Operator: "neq", Value: "guest"}},
+		100,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{
+		{"role": "member", "count": 3, "max_user_id": 9},
+		{"role": "owner", "count": 1, "max_user_id": 7},
+	}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAggregate_WithoutGroups(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(DISTINCT `name`) AS `count_distinct_name` FROM `legacy`")).
+		WillReturnRows(sqlmock.NewRows([]string{"count_distinct_name"}).AddRow(int64(4)))
+
+	rows, err := newLegacyRepo(db).Aggregate(context.Background(),
+		[]helper.Aggregate{{Func: "count_distinct", Field: "name"}}, nil, nil, 100)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"count_distinct_name": 4}}, rows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAggregateColumns(t *testing.T) {
+	require.ElementsMatch(t, []string{"id", "name"}, repository.AggregateColumns(&legacyRecord{}))
+}
@@ -1,6 +1,7 @@
 package repository_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"regexp"
@@ -14,7 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func newTestRepo(db *sql.DB) repository.RepositoryInterface[*models.Example] {
+func newTestRepo(db *sql.DB) *repository.Repository[*models.Example] {
 	return repository.NewRepository[*models.Example](db, func() *models.Example {
 		return &models.Example{}
 	})
@@ -32,7 +33,7 @@ func TestAdd(t *testing.T) {
 		WithArgs(example.ID, example.Name, example.Age, nil, nil, nil).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Add(example)
+	err = repo.Add(context.Background(), example)
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -48,7 +49,7 @@ func TestEdit(t *testing.T) {
 		WithArgs("Jane", "1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Edit("`example`", "id", "1", []string{"name"}, []interface{}{"Jane"})
+	err = repo.Edit(context.Background(), "`example`", "id", "1", []string{"name"}, []interface{}{"Jane"})
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -64,7 +65,7 @@ func TestDelete(t *testing.T) {
 		WithArgs("1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Delete(&models.Example{ID: "1"})
+	err = repo.Delete(context.Background(), &models.Example{ID: "1"})
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -80,7 +81,7 @@ func TestUndelete(t *testing.T) {
 		WithArgs("1").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = repo.Undelete(&models.Example{ID: "1"})
+	err = repo.Undelete(context.Background(), &models.Example{ID: "1"})
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -97,7 +98,7 @@ func TestDetail(t *testing.T) {
 		WithArgs("1").
 		WillReturnRows(rows)
 
-	result, err := repo.Detail("1", []string{"id", "name", "age"})
+	result, err := repo.Detail(context.Background(), "1", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Equal(t, "John", result["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -115,7 +116,7 @@ func TestDeadDetail(t *testing.T) {
 		WithArgs("1").
 		WillReturnRows(rows)
 
-	result, err := repo.DeadDetail("1", []string{"id", "name", "age"})
+	result, err := repo.DeadDetail(context.Background(), "1", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Equal(t, "John", result["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -128,7 +129,7 @@ func TestEditEmptyColumns(t *testing.T) {
 
 	repo := newTestRepo(db)
 
-	err = repo.Edit("example", "id", "1", []string{}, []interface{}{})
+	err = repo.Edit(context.Background(), "example", "id", "1", []string{}, []interface{}{})
 	require.NoError(t, err)
 }
 
@@ -143,7 +144,7 @@ func TestDetail_Error(t *testing.T) {
 		WithArgs("non-existent").
 		WillReturnError(sql.ErrConnDone)
 
-	_, err = repo.Detail("non-existent", []string{"id", "name", "age"})
+	_, err = repo.Detail(context.Background(), "non-existent", []string{"id", "name", "age"})
 	require.Error(t, err)
 	require.Equal(t, sql.ErrConnDone, err)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -160,7 +161,7 @@ func TestDetaill_NoRows(t *testing.T) {
 		WithArgs("not-found").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}))
 
-	result, err := repo.Detail("not-found", []string{"id", "name", "age"})
+	result, err := repo.Detail(context.Background(), "not-found", []string{"id", "name", "age"})
 	require.ErrorIs(t, err, sql.ErrNoRows)
 	require.Nil(t, result)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -180,7 +181,7 @@ func TestList(t *testing.T) {
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	result, err := repo.List(10, nil, "id", "asc", []string{"id", "name", "age"}, nil)
+	result, err := repo.List(context.Background(), 10, 0, nil, "id", "asc", []string{"id", "name", "age"}, nil)
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
@@ -201,7 +202,7 @@ func TestDeadList(t *testing.T) {
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	result, err := repo.DeadList(10, nil, "id", "asc", []string{"id", "name", "age"}, nil)
+	result, err := repo.DeadList(context.Background(), 10, 0, nil, "id", "asc", []string{"id", "name", "age"}, nil)
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
@@ -223,13 +224,37 @@ func TestListWithFilters(t *testing.T) {
 		WillReturnRows(rows)
 
 	filters := []helper.Filter{{Field: "name", Operator: "eql", Value: "John"}}
-	result, err := repo.List(10, nil, "id", "asc", []string{"id", "name", "age"}, filters)
+	result, err := repo.List(context.Background(), 10, 0, nil, "id", "asc", []string{"id", "name", "age"}, filters)
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestListWithFilterGroup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "age"}).AddRow("1", "John", 30)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `name`, `age` FROM `example` WHERE (`age` > ? OR `name` LIKE ? ESCAPE '!') AND `deleted_at` IS NULL ORDER BY `id` DESC LIMIT ?",
+	)).
+		WithArgs("30", "%Jo!_n%", 10).
+		WillReturnRows(rows)
+
+	filters := []helper.Filter{{Operator: "or", Group: []helper.Filter{
+		{Field: "age", Operator: "gt", Value: "30"},
+		{Field: "name", Operator: "lik", Value: "Jo_n"},
+	}}}
+	result, err := repo.List(context.Background(), 10, 0, nil, "id", "DESC", []string{"id", "name", "age"}, filters)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeadListWithFilters(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -245,7 +270,7 @@ func TestDeadListWithFilters(t *testing.T) {
 		WillReturnRows(rows)
 
 	filters := []helper.Filter{{Field: "name", Operator: "eql", Value: "John"}}
-	result, err := repo.DeadList(10, nil, "id", "asc", []string{"id", "name", "age"}, filters)
+	result, err := repo.DeadList(context.Background(), 10, 0, nil, "id", "asc", []string{"id", "name", "age"}, filters)
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
@@ -265,7 +290,7 @@ func TestList_QueryError(t *testing.T) {
 		WithArgs(10).
 		WillReturnError(sql.ErrConnDone)
 
-	result, err := repo.List(10, nil, "id", "asc", []string{"id", "name", "age"}, nil)
+	result, err := repo.List(context.Background(), 10, 0, nil, "id", "asc", []string{"id", "name", "age"}, nil)
 	require.Error(t, err)
 	require.Nil(t, result)
 	require.Equal(t, sql.ErrConnDone, err)
@@ -294,7 +319,7 @@ func TestList_ScanError(t *testing.T) {
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	result, err := repo.List(10, nil, "id", "asc", []string{"id", "name", "age"}, nil)
+	result, err := repo.List(context.Background(), 10, 0, nil, "id", "asc", []string{"id", "name", "age"}, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "scan error")
 	require.Nil(t, result)
@@ -315,7 +340,7 @@ func TestBulk(t *testing.T) {
 		WithArgs("1", "2", 10).
 		WillReturnRows(rows)
 
-	result, err := repo.Bulk([]string{"1", "2"}, 10, nil, "id", "asc", []string{"id", "name", "age"})
+	result, err := repo.Bulk(context.Background(), []string{"1", "2"}, 10, 0, nil, "id", "asc", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
@@ -329,7 +354,7 @@ func TestBulk_EmptyIDs(t *testing.T) {
 
 	repo := newTestRepo(db)
 
-	result, err := repo.Bulk([]string{}, 10, nil, "id", "asc", []string{"id", "name", "age"})
+	result, err := repo.Bulk(context.Background(), []string{}, 10, 0, nil, "id", "asc", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Nil(t, result)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -349,7 +374,7 @@ func TestBulk_QueryError(t *testing.T) {
 		WithArgs("1", "2", 10).
 		WillReturnError(expectedErr)
 
-	result, err := repo.Bulk([]string{"1", "2"}, 10, nil, "id", "asc", []string{"id", "name", "age"})
+	result, err := repo.Bulk(context.Background(), []string{"1", "2"}, 10, 0, nil, "id", "asc", []string{"id", "name", "age"})
 	require.Error(t, err)
 	require.Equal(t, expectedErr, err)
 	require.Nil(t, result)
@@ -379,77 +404,197 @@ func TestBulk_ScanError(t *testing.T) {
 
 	repo := newTestRepo(db)
 
-	result, err := repo.Bulk([]string{"1", "2"}, 10, nil, "id", "asc", []string{"id", "name", "age"})
+	result, err := repo.Bulk(context.Background(), []string{"1", "2"}, 10, 0, nil, "id", "asc", []string{"id", "name", "age"})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "scan error")
 	require.Nil(t, result)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func issuedCursor(t *testing.T, token string) *helper.PageCursor {
+	t.Helper()
+	c, err := helper.DecodeCursor(token)
+	require.NoError(t, err)
+	return &c
+}
+
 func TestList_WithCursor(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
 	repo := newTestRepo(db)
-	cursor := &helper.PageCursor{
-		LastID:    "2",
-		LastValue: "42",
-	}
+	next, _ := repo.PageCursors([]map[string]any{{"id": "2", "name": "Bob", "age": 42}}, 1, nil, "id", "desc", nil)
+	cursor := issuedCursor(t, next)
 
 	query := regexp.QuoteMeta(
 		"SELECT `id`, `name`, `age` FROM `example` " +
-			"WHERE `deleted_at` IS NULL AND ( `id` < ? OR ( `id` = ? AND `id` < ? ) ) " +
+			"WHERE `deleted_at` IS NULL AND `id` < ? " +
 			"ORDER BY `id` DESC LIMIT ?",
 	)
 
 	mock.ExpectQuery(query).
-		WithArgs(cursor.LastValue, cursor.LastValue, cursor.LastID, 10).
+		WithArgs("2", 10).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).
-			AddRow("3", "Alice", 25),
+			AddRow("1", "Alice", 25),
 		)
 
-	list, err := repo.List(10, cursor, "id", "desc", []string{"id", "name", "age"}, nil)
+	list, err := repo.List(context.Background(), 10, 0, cursor, "id", "desc", []string{"id", "name", "age"}, nil)
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, "Alice", list[0]["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestList_WithCursorOnColumn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	next, _ := repo.PageCursors([]map[string]any{{"id": "2", "age": 42}}, 1, nil, "age", "ASC", nil)
+	cursor := issuedCursor(t, next)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `age` FROM `example` "+
+			"WHERE `deleted_at` IS NULL AND ( `age` > ? OR ( `age` = ? AND `id` > ? ) ) "+
+			"ORDER BY `age` ASC, `id` ASC LIMIT ?",
+	)).
+		WithArgs(42, 42, "2", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).AddRow("3", 50))
+
+	list, err := repo.List(context.Background(), 10, 0, cursor, "age", "ASC", []string{"id", "age"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_WithCursorAfterNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	next, _ := repo.PageCursors([]map[string]any{{"id": "2", "age": nil}}, 1, nil, "age", "ASC", nil)
+	cursor := issuedCursor(t, next)
+	require.Equal(t, []any{nil, "2"}, cursor.Values)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `age` FROM `example` "+
+			"WHERE `deleted_at` IS NULL AND ( ( `age` IS NULL AND `id` > ? ) OR `age` IS NOT NULL ) "+
+			"ORDER BY `age` ASC, `id` ASC LIMIT ?",
+	)).
+		WithArgs("2", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).AddRow("3", 50))
+
+	list, err := repo.List(context.Background(), 10, 0, cursor, "age", "ASC", []string{"id", "age"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_WithBackwardCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	page := []map[string]any{{"id": "5", "age": 30}, {"id": "6", "age": 31}}
+	current := &helper.PageCursor{Values: []any{29, "4"}, OrderBy: "age:ASC,id:ASC"}
+	_, prev := repo.PageCursors(page, 2, current, "age", "ASC", nil)
+	cursor := issuedCursor(t, prev)
+	require.True(t, cursor.Backward)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `age` FROM `example` "+
+			"WHERE `deleted_at` IS NULL AND ( `age` < ? OR ( `age` = ? AND `id` < ? ) OR `age` IS NULL ) "+
+			"ORDER BY `age` DESC, `id` DESC LIMIT ?",
+	)).
+		WithArgs(30, 30, "5", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "age"}).
+			AddRow("4", 29).
+			AddRow("3", nil))
+
+	list, err := repo.List(context.Background(), 2, 0, cursor, "age", "ASC", []string{"id", "age"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"id": "3", "age": nil}, {"id": "4", "age": 29}}, list)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_WithCursorOnSeveralColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	next, _ := repo.PageCursors([]map[string]any{{"id": "2", "name": "Bob", "age": 42}}, 1, nil, "name:asc,age:desc", "DESC", nil)
+	cursor := issuedCursor(t, next)
+	require.Equal(t, []any{"Bob", int64(42), "2"}, cursor.Values)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `name`, `age` FROM `example` "+
+			"WHERE `deleted_at` IS NULL AND ( `name` > ? OR ( `name` = ? AND ( `age` < ? OR ( `age` = ? AND `id` < ? ) OR `age` IS NULL ) ) ) "+
+			"ORDER BY `name` ASC, `age` DESC, `id` DESC LIMIT ?",
+	)).
+		WithArgs("Bob", "Bob", int64(42), int64(42), "2", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).AddRow("1", "Bob", 30))
+
+	list, err := repo.List(context.Background(), 10, 0, cursor, "name:asc,age:desc", "DESC", []string{"id", "name", "age"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	_, err = repo.List(context.Background(), 10, 0, cursor, "name:asc,age:asc", "DESC", nil, nil)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_CursorForOtherQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	filters := []helper.Filter{{Field: "age", Operator: "gt", Value: "18"}}
+	next, _ := repo.PageCursors([]map[string]any{{"id": "2", "age": 42}}, 1, nil, "age", "ASC", filters)
+	cursor := issuedCursor(t, next)
+
+	_, err = repo.List(context.Background(), 10, 0, cursor, "age", "DESC", nil, filters)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+	_, err = repo.List(context.Background(), 10, 0, cursor, "name", "ASC", nil, filters)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+	_, err = repo.List(context.Background(), 10, 0, cursor, "age", "ASC", nil, nil)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestBulk_WithCursor(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
 	defer db.Close()
 
 	repo := newTestRepo(db)
-	cursor := &helper.PageCursor{
-		LastID:    "100",
-		LastValue: "20",
-	}
 	ids := []string{"1", "2"}
+	next, _ := repo.BulkPageCursors([]map[string]any{{"id": "1"}}, ids, 1, nil, "id", "ASC")
+	cursor := issuedCursor(t, next)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT `id`, `name`, `age` FROM `example` WHERE `deleted_at` IS NULL "+
-			"AND (`id` > ? OR (`id` = ? AND `id` > ?)) "+
+			"AND `id` > ? "+
 			"AND `id` IN (?, ?) ORDER BY `id` ASC LIMIT ?",
 	)).
-		WithArgs(
-			cursor.LastValue,
-			cursor.LastValue,
-			cursor.LastID,
-			ids[0],
-			ids[1],
-			5,
-		).
+		WithArgs("1", ids[0], ids[1], 5).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).
 			AddRow("2", "Bob", 28),
 		)
 
-	list, err := repo.Bulk(ids, 5, cursor, "id", "ASC", []string{"id", "name", "age"})
+	list, err := repo.Bulk(context.Background(), ids, 5, 0, cursor, "id", "ASC", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, "Bob", list[0]["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = repo.Bulk(context.Background(), []string{"1", "3"}, 5, 0, cursor, "id", "ASC", nil)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
 }
 
 func TestBulk_WithCursorDesc(t *testing.T) {
@@ -458,30 +603,21 @@ func TestBulk_WithCursorDesc(t *testing.T) {
 	defer db.Close()
 
 	repo := newTestRepo(db)
-	cursor := &helper.PageCursor{
-		LastID:    "100",
-		LastValue: "20",
-	}
 	ids := []string{"1", "2"}
+	next, _ := repo.BulkPageCursors([]map[string]any{{"id": "2", "age": 20}}, ids, 1, nil, "age", "DESC")
+	cursor := issuedCursor(t, next)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
 		"SELECT `id`, `name`, `age` FROM `example` WHERE `deleted_at` IS NULL "+
-			"AND (`id` < ? OR (`id` = ? AND `id` < ?)) "+
-			"AND `id` IN (?, ?) ORDER BY `id` DESC LIMIT ?",
+			"AND ( `age` < ? OR ( `age` = ? AND `id` < ? ) OR `age` IS NULL ) "+
+			"AND `id` IN (?, ?) ORDER BY `age` DESC, `id` DESC LIMIT ?",
 	)).
-		WithArgs(
-			cursor.LastValue,
-			cursor.LastValue,
-			cursor.LastID,
-			ids[0],
-			ids[1],
-			5,
-		).
+		WithArgs(20, 20, "2", ids[0], ids[1], 5).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).
-			AddRow("2", "Bob", 28),
+			AddRow("1", "Bob", 18),
 		)
 
-	list, err := repo.Bulk(ids, 5, cursor, "id", "DESC", []string{"id", "name", "age"})
+	list, err := repo.Bulk(context.Background(), ids, 5, 0, cursor, "age", "DESC", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Len(t, list, 1)
 	require.Equal(t, "Bob", list[0]["name"])
@@ -501,7 +637,7 @@ func TestListOne_Success(t *testing.T) {
 		"SELECT `id`, `name`, `age` FROM `example` WHERE `deleted_at` IS NULL ORDER BY `id` ASC LIMIT ?",
 	)).WithArgs(1).WillReturnRows(rows)
 
-	result, err := repo.ListOne("id", "ASC", fields, nil)
+	result, err := repo.ListOne(context.Background(), "id", "ASC", fields, nil)
 	require.NoError(t, err)
 	require.Equal(t, "John", result["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -519,7 +655,7 @@ func TestListOne_Empty(t *testing.T) {
 		"SELECT `id`, `name`, `age` FROM `example` WHERE `deleted_at` IS NULL ORDER BY `id` ASC LIMIT ?",
 	)).WithArgs(1).WillReturnRows(sqlmock.NewRows(fields))
 
-	result, err := repo.ListOne("id", "ASC", fields, nil)
+	result, err := repo.ListOne(context.Background(), "id", "ASC", fields, nil)
 	require.NoError(t, err)
 	require.Empty(t, result)
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -538,7 +674,7 @@ func TestListOne_Error(t *testing.T) {
 		"SELECT `id`, `name`, `age` FROM `example` WHERE `deleted_at` IS NULL ORDER BY `id` ASC LIMIT ?",
 	)).WithArgs(1).WillReturnError(expErr)
 
-	result, err := repo.ListOne("id", "ASC", fields, nil)
+	result, err := repo.ListOne(context.Background(), "id", "ASC", fields, nil)
 	require.Error(t, err)
 	require.Equal(t, expErr, err)
 	require.Empty(t, result)
@@ -563,7 +699,7 @@ func TestRepository_Raw_Success(t *testing.T) {
 			AddRow("1", "Alice"),
 		)
 
-	results, err := repo.Raw(rawQuery, params)
+	results, err := repo.Raw(context.Background(), rawQuery, params)
 	require.NoError(t, err)
 	require.Len(t, results, 1)
 	require.Equal(t, "Alice", results[0]["name"])
@@ -587,7 +723,7 @@ func TestRepository_Raw_QueryError(t *testing.T) {
 		WithArgs(args[0]).
 		WillReturnError(fmt.Errorf("db exploded"))
 
-	results, err := repo.Raw(rawQuery, params)
+	results, err := repo.Raw(context.Background(), rawQuery, params)
 	require.Error(t, err)
 	require.Nil(t, results)
 	require.Contains(t, err.Error(), "db exploded")
@@ -633,7 +769,7 @@ func TestBulkAdd_Success(t *testing.T) {
 		).
 		WillReturnResult(sqlmock.NewResult(1, 2))
 
-	err = repo.BulkAdd([]*models.Example{e1, e2})
+	err = repo.BulkAdd(context.Background(), []*models.Example{e1, e2})
 	require.NoError(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -656,7 +792,7 @@ func TestBulkAdd_Error(t *testing.T) {
 		WithArgs("x", "Y", 10, nil, nil, nil).
 		WillReturnError(fmt.Errorf("insert failed"))
 
-	err = repo.BulkAdd([]*models.Example{e})
+	err = repo.BulkAdd(context.Background(), []*models.Example{e})
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "insert failed")
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -669,7 +805,7 @@ func TestBulkAdd_EmptySlicePanics(t *testing.T) {
 
 	repo := newTestRepo(db)
 	require.Panics(t, func() {
-		_ = repo.BulkAdd([]*models.Example{})
+		_ = repo.BulkAdd(context.Background(), []*models.Example{})
 	})
 }
 
@@ -705,7 +841,7 @@ func TestList_OrderByNotID_AddsSecondaryID(t *testing.T) {
 		WithArgs(10).
 		WillReturnRows(rows)
 
-	result, err := repo.List(10, nil, "name", "DESC", []string{"id", "name", "age"}, nil)
+	result, err := repo.List(context.Background(), 10, 0, nil, "name", "DESC", []string{"id", "name", "age"}, nil)
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
@@ -727,9 +863,170 @@ func TestBulk_OrderByNotID_AddsSecondaryID(t *testing.T) {
 		WithArgs("1", "2", 10).
 		WillReturnRows(rows)
 
-	result, err := repo.Bulk([]string{"1", "2"}, 10, nil, "name", "DESC", []string{"id", "name", "age"})
+	result, err := repo.Bulk(context.Background(), []string{"1", "2"}, 10, 0, nil, "name", "DESC", []string{"id", "name", "age"})
 	require.NoError(t, err)
 	require.Len(t, result, 1)
 	require.Equal(t, "John", result[0]["name"])
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func usePostgres(t *testing.T) {
+	helper.SetDialect(helper.PostgresDialect{})
+	t.Cleanup(func() { helper.SetDialect(helper.MySQLDialect{}) })
+}
+
+func TestAdd_Postgres(t *testing.T) {
+	usePostgres(t)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	example := &models.Example{ID: "1", Name: "John", Age: 30}
+	repo := newTestRepo(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "example" ("id", "name", "age", "created_at", "updated_at", "deleted_at") VALUES ($1, $2, $3, $4, $5, $6)`)).
+		WithArgs(example.ID, example.Name, example.Age, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.Add(context.Background(), example)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelete_Postgres(t *testing.T) {
+	usePostgres(t)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "example" SET "deleted_at" = CURRENT_TIMESTAMP WHERE "id" = $1 AND "deleted_at" IS NULL`)).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.Delete(context.Background(), &models.Example{ID: "1"})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_WithCursorAndFilters_Postgres(t *testing.T) {
+	usePostgres(t)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	filters := []helper.Filter{{Field: "age", Operator: "gt", Value: "18"}}
+	next, _ := repo.PageCursors([]map[string]any{{"id": "2", "name": "John"}}, 1, nil, "name", "DESC", filters)
+	cursor := issuedCursor(t, next)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT "id", "name", "age" FROM "example" `+
+			`WHERE "age" > $1 AND "deleted_at" IS NULL AND ( "name" < $2 OR ( "name" = $3 AND "id" < $4 ) OR "name" IS NULL ) `+
+			`ORDER BY "name" DESC NULLS LAST, "id" DESC LIMIT $5`,
+	)).
+		WithArgs("18", "John", "John", "2", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "age"}).AddRow("1", "Alice", 25))
+
+	list, err := repo.List(context.Background(), 10, 0, cursor, "name", "DESC", []string{"id", "name", "age"}, filters)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+func TestBulkAdd_Postgres(t *testing.T) {
+	usePostgres(t)
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	e := &models.Example{ID: "x", Name: "Y", Age: 10}
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "example" ("id", "name", "age", "last_seen", "last_login", "created_at", "updated_at", "deleted_at") VALUES `+
+			`($1, $2, $3, DEFAULT, DEFAULT, $4, $5, $6)`,
+	)).
+		WithArgs("x", "Y", 10, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.BulkAdd(context.Background(), []*models.Example{e})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkAdd_SQLite_GroupsRowsByDefaultedColumns(t *testing.T) {
+	helper.SetDialect(helper.SQLiteDialect{})
+	t.Cleanup(func() { helper.SetDialect(helper.MySQLDialect{}) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	seen := helper.JSONTime(time.Now().Truncate(time.Second))
+	e1 := &models.Example{ID: "1", Name: "Alice", Age: 25}
+	e2 := &models.Example{ID: "2", Name: "Bob", Age: 10, LastSeen: &seen}
+	e3 := &models.Example{ID: "3", Name: "Carol", Age: 40}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "example" ("id", "name", "age", "created_at", "updated_at", "deleted_at") VALUES `+
+			`(?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?)`,
+	)).
+		WithArgs("1", "Alice", 25, nil, nil, nil, "3", "Carol", 40, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "example" ("id", "name", "age", "last_seen", "created_at", "updated_at", "deleted_at") VALUES `+
+			`(?, ?, ?, ?, ?, ?, ?)`,
+	)).
+		WithArgs("2", "Bob", 10, &seen, nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.BulkAdd(context.Background(), []*models.Example{e1, e2, e3})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkAdd_SQLite_RollsBackOnError(t *testing.T) {
+	helper.SetDialect(helper.SQLiteDialect{})
+	t.Cleanup(func() { helper.SetDialect(helper.MySQLDialect{}) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	seen := helper.JSONTime(time.Now().Truncate(time.Second))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO").WillReturnError(fmt.Errorf("UNIQUE constraint failed: example.id"))
+	mock.ExpectRollback()
+
+	err = repo.BulkAdd(context.Background(), []*models.Example{
+		{ID: "1", Name: "Alice", Age: 25},
+		{ID: "1", Name: "Bob", Age: 10, LastSeen: &seen},
+	})
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_ContextCanceled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectQuery("SELECT").WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = repo.List(ctx, 10, 0, nil, "id", "asc", []string{"id"}, nil)
+	require.Error(t, err)
+	require.True(t, helper.IsTimeoutError(ctx, err))
+}
@@ -0,0 +1,137 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkEdit_CompositeKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	update := regexp.QuoteMeta(
+		"UPDATE `membership` SET `role` = ? WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL",
+	)
+	mock.ExpectBegin()
+	mock.ExpectExec(update).
+		WithArgs("member", int64(7), "admins").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(update).
+		WithArgs("member", int64(8), "users").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `membership` WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL")).
+		WithArgs(int64(8), "users").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "group_id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `membership` WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NOT NULL")).
+		WithArgs(int64(8), "users").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "group_id"}))
+	mock.ExpectCommit()
+
+	ids := []interface{}{[]interface{}{int64(7), "admins"}, []interface{}{int64(8), "users"}}
+	results, err := newMembershipRepo(db).BulkEdit(context.Background(), ids, []string{"role"}, []interface{}{"member"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, repository.BulkResult{Affected: 1}, results[0])
+	require.ErrorIs(t, results[1].Err, sql.ErrNoRows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkEdit_UnchangedRowIsEdited(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `name` = ? WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("Alice", "01").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("01").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("01"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `name` = ? WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("Alice", "02").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("02").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `example` WHERE `id` = ? AND `deleted_at` IS NOT NULL")).
+		WithArgs("02").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("02"))
+	mock.ExpectCommit()
+
+	results, err := newTestRepo(db).BulkEdit(context.Background(), []interface{}{"01", "02"}, []string{"name"}, []interface{}{"Alice"})
+	require.NoError(t, err)
+	require.Equal(t, repository.BulkResult{}, results[0])
+	require.ErrorIs(t, results[1].Err, repository.ErrAlreadyDeleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkEditWhere(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `example` SET `name` = ? WHERE `age` > ? AND `deleted_at` IS NULL",
+	)).
+		WithArgs("Adult", "17").
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	filters := []helper.Filter{{Field: "age", Operator: "gt", Value: "17"}}
+	n, err := newTestRepo(db).BulkEditWhere(context.Background(), filters, []string{"name"}, []interface{}{"Adult"})
+	require.NoError(t, err)
+	require.Equal(t, int64(42), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkEditWhere_RequiresFilter(t *testing.T) {
+	_, err := newTestRepo(nil).BulkEditWhere(context.Background(), nil, []string{"name"}, []interface{}{"x"})
+	require.ErrorIs(t, err, repository.ErrMissingFilter)
+}
+
+func TestBulkDelete_RollsBackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` = ?")).
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` = ?")).
+		WithArgs(int64(2)).
+		WillReturnError(errors.New("locked"))
+	mock.ExpectRollback()
+
+	_, err = newLegacyRepo(db).BulkDelete(context.Background(), []interface{}{int64(1), int64(2)})
+	require.EqualError(t, err, "locked")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkUndelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `example` SET `deleted_at` = NULL WHERE `id` = ? AND `deleted_at` IS NOT NULL",
+	)).
+		WithArgs("01").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	results, err := newTestRepo(db).BulkUndelete(context.Background(), []interface{}{"01"})
+	require.NoError(t, err)
+	require.Equal(t, []repository.BulkResult{{Affected: 1}}, results)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount_WithFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `membership` WHERE `role` = ? AND `deleted_at` IS NULL")).
+		WithArgs("owner").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+
+	n, err := newMembershipRepo(db).Count(context.Background(), []helper.Filter{{Field: "role", Operator: "eql", Value: "owner"}})
+	require.NoError(t, err)
+	require.Equal(t, int64(12), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeadCount(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `membership` WHERE `deleted_at` IS NOT NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	n, err := newMembershipRepo(db).DeadCount(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	_, err = newLegacyRepo(db).DeadCount(context.Background(), nil)
+	require.ErrorIs(t, err, repository.ErrNotSoftDeletable)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkCount_CompositeKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT COUNT(*) FROM `membership` WHERE `deleted_at` IS NULL AND (`user_id`, `group_id`) IN ((?, ?), (?, ?))",
+	)).
+		WithArgs("7", "admins", "8", "users").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	n, err := newMembershipRepo(db).BulkCount(context.Background(), []string{"7/admins", "8/users"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_Offset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `name` FROM `legacy` ORDER BY `name` ASC, `id` ASC LIMIT ? OFFSET ?",
+	)).
+		WithArgs(10, 30).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("x"))
+
+	list, err := newLegacyRepo(db).List(context.Background(), 10, 30, nil, "name", "ASC", []string{"name"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
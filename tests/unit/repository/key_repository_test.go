@@ -0,0 +1,291 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type membership struct {
+	UserID    int64      `json:"user_id"`
+	GroupID   string     `json:"group_id"`
+	Role      string     `json:"role"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func (m *membership) Schema() map[string]string {
+	return map[string]string{
+		"user_id":    "int",
+		"group_id":   "string",
+		"role":       "string",
+		"created_at": "*time.Time",
+		"updated_at": "*time.Time",
+		"deleted_at": "*time.Time",
+	}
+}
+
+func (m *membership) TableName() string {
+	return "`membership`"
+}
+
+func (m *membership) Columns() []string {
+	return []string{"user_id", "group_id", "role", "created_at", "updated_at", "deleted_at"}
+}
+
+func (m *membership) Values() []interface{} {
+	return []interface{}{m.UserID, m.GroupID, m.Role, m.CreatedAt, m.UpdatedAt, m.DeletedAt}
+}
+
+func (m *membership) HasDefaultValue() []string {
+	return []string{}
+}
+
+func (m *membership) PrimaryKey() string {
+	return "user_id"
+}
+
+func (m *membership) PrimaryKeyValue() interface{} {
+	return m.UserID
+}
+
+func (m *membership) SoftDeletePolicy() repository.SoftDeletePolicy {
+	return repository.SoftDeletePolicy{}
+}
+
+func (m *membership) PrimaryKeys() []string {
+	return []string{"user_id", "group_id"}
+}
+
+func (m *membership) PrimaryKeyValues() []interface{} {
+	return []interface{}{m.UserID, m.GroupID}
+}
+
+func newMembershipRepo(db *sql.DB) *repository.Repository[*membership] {
+	return repository.NewRepository(db, func() *membership {
+		return &membership{}
+	})
+}
+
+func TestPrimaryKeys(t *testing.T) {
+	m := &membership{UserID: 7, GroupID: "admins"}
+	require.Equal(t, []string{"user_id", "group_id"}, repository.PrimaryKeys(m))
+	require.Equal(t, []interface{}{int64(7), "admins"}, repository.PrimaryKeyValues(m))
+
+	require.Equal(t, []string{"id"}, repository.PrimaryKeys(&versionedExample{}))
+}
+
+func TestCompositeKey_Detail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `user_id`, `role` FROM `membership` WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL",
+	)).
+		WithArgs(int64(7), "admins").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}).AddRow(7, "owner"))
+
+	row, err := newMembershipRepo(db).Detail(context.Background(), []interface{}{int64(7), "admins"}, []string{"user_id", "role"})
+	require.NoError(t, err)
+	require.Equal(t, 7, row["user_id"])
+	require.Equal(t, "owner", row["role"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompositeKey_DeleteAndEdit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newMembershipRepo(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `membership` SET `deleted_at` = NOW() WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL",
+	)).
+		WithArgs(int64(7), "admins").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `membership` SET `role` = ? WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL",
+	)).
+		WithArgs("member", int64(7), "admins").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Delete(context.Background(), &membership{UserID: 7, GroupID: "admins"}))
+	require.NoError(t, repo.Edit(context.Background(), "`membership`", "user_id", []interface{}{int64(7), "admins"}, []string{"role"}, []interface{}{"member"}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompositeKey_ListWithCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newMembershipRepo(db)
+	next, _ := repo.PageCursors([]map[string]any{{"user_id": 7, "group_id": "admins", "role": "owner"}}, 1, nil, "role", "ASC", nil)
+	cursor, err := helper.DecodeCursor(next)
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `user_id`, `group_id`, `role` FROM `membership` "+
+			"WHERE `deleted_at` IS NULL AND ( `role` > ? OR ( `role` = ? AND (`user_id`, `group_id`) > (?, ?) ) ) "+
+			"ORDER BY `role` ASC, `user_id` ASC, `group_id` ASC LIMIT ?",
+	)).
+		WithArgs("owner", "owner", 7, "admins", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "group_id", "role"}).AddRow(8, "admins", "owner"))
+
+	list, err := repo.List(context.Background(), 10, 0, &cursor, "role", "ASC", []string{"user_id", "group_id", "role"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompositeKey_ListInvalidCursor(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	cursor := &helper.PageCursor{Values: []any{"owner", int64(7)}, OrderBy: "role:ASC,user_id:ASC,group_id:ASC"}
+
+	_, err = newMembershipRepo(db).List(context.Background(), 10, 0, cursor, "role", "ASC", []string{"role"}, nil)
+	require.ErrorContains(t, err, "must hold 3 values")
+}
+
+func TestCompositeKey_Bulk(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `user_id`, `group_id` FROM `membership` "+
+			"WHERE `deleted_at` IS NULL AND (`user_id`, `group_id`) IN ((?, ?), (?, ?)) "+
+			"ORDER BY `user_id` DESC, `group_id` DESC LIMIT ?",
+	)).
+		WithArgs("7", "admins", "8", "users", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "group_id"}).AddRow(8, "users"))
+
+	list, err := newMembershipRepo(db).Bulk(context.Background(), []string{"7/admins", "8/users"}, 25, 0, nil, "user_id", "desc", []string{"user_id", "group_id"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type legacyRecord struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (m *legacyRecord) Schema() map[string]string {
+	return map[string]string{"id": "int", "name": "string"}
+}
+
+func (m *legacyRecord) TableName() string {
+	return "legacy"
+}
+
+func (m *legacyRecord) Columns() []string {
+	return []string{"id", "name"}
+}
+
+func (m *legacyRecord) Values() []interface{} {
+	return []interface{}{m.ID, m.Name}
+}
+
+func (m *legacyRecord) HasDefaultValue() []string {
+	return []string{}
+}
+
+func (m *legacyRecord) PrimaryKey() string {
+	return "id"
+}
+
+func (m *legacyRecord) PrimaryKeyValue() interface{} {
+	return m.ID
+}
+
+func (m *legacyRecord) SetInsertID(id int64) {
+	m.ID = id
+}
+
+func newLegacyRepo(db *sql.DB) *repository.Repository[*legacyRecord] {
+	return repository.NewRepository(db, func() *legacyRecord {
+		return &legacyRecord{}
+	})
+}
+
+func TestAutoIncrement_AddReadsLastInsertID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy` (`name`) VALUES (?)")).
+		WithArgs("old").
+		WillReturnResult(sqlmock.NewResult(41, 1))
+
+	m := &legacyRecord{Name: "old"}
+	require.NoError(t, newLegacyRepo(db).Add(context.Background(), m))
+	require.Equal(t, int64(41), m.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAutoIncrement_AddKeepsSuppliedID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy` (`id`, `name`) VALUES (?, ?)")).
+		WithArgs(int64(9), "old").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	m := &legacyRecord{ID: 9, Name: "old"}
+	require.NoError(t, newLegacyRepo(db).Add(context.Background(), m))
+	require.Equal(t, int64(9), m.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAutoIncrement_AddPostgresReturning(t *testing.T) {
+	helper.SetDialect(helper.PostgresDialect{})
+	t.Cleanup(func() { helper.SetDialect(helper.MySQLDialect{}) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "legacy" ("name") VALUES ($1) RETURNING "id"`)).
+		WithArgs("old").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(77))
+
+	m := &legacyRecord{Name: "old"}
+	require.NoError(t, newLegacyRepo(db).Add(context.Background(), m))
+	require.Equal(t, int64(77), m.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAutoIncrement_BulkAddInsertsRowByRow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy` (`name`) VALUES (?)")).
+		WithArgs("a").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy` (`name`) VALUES (?)")).
+		WithArgs("b").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	items := []*legacyRecord{{Name: "a"}, {Name: "b"}}
+	require.NoError(t, newLegacyRepo(db).BulkAdd(context.Background(), items))
+	require.Equal(t, int64(1), items[0].ID)
+	require.Equal(t, int64(2), items[1].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
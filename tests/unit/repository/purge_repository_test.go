@@ -0,0 +1,152 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type retainedRecord struct {
+	legacyRecord
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func (m *retainedRecord) Columns() []string {
+	return []string{"id", "name", "deleted_at"}
+}
+
+func (m *retainedRecord) SoftDeletePolicy() repository.SoftDeletePolicy {
+	return repository.SoftDeletePolicy{PurgeAfter: 90 * 24 * time.Hour, Archive: true}
+}
+
+func newRetainedRepo(db *sql.DB) *repository.Repository[*retainedRecord] {
+	return repository.NewRepository(db, func() *retainedRecord {
+		return &retainedRecord{}
+	})
+}
+
+func TestPurge_ArchivesAndDeletes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `legacy_archive` (`id`, `name`, `deleted_at`) SELECT `id`, `name`, `deleted_at` FROM `legacy` " +
+			"WHERE `id` IN (?) AND `deleted_at` IS NOT NULL",
+	)).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` IN (?) AND `deleted_at` IS NOT NULL")).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m := &retainedRecord{legacyRecord: legacyRecord{ID: 5}}
+	require.NoError(t, newRetainedRepo(db).Purge(context.Background(), m))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurge_NotDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(
+		"DELETE FROM `membership` WHERE (`user_id`, `group_id`) IN ((?, ?)) AND `deleted_at` IS NOT NULL",
+	)).
+		WithArgs(int64(7), "admins").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `user_id`, `group_id` FROM `membership` WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL LIMIT 1",
+	)).
+		WithArgs(int64(7), "admins").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "group_id"}).AddRow(7, "admins"))
+	mock.ExpectRollback()
+
+	err = newMembershipRepo(db).Purge(context.Background(), &membership{UserID: 7, GroupID: "admins"})
+	require.ErrorIs(t, err, repository.ErrNotDeleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurge_NotSoftDeletable(t *testing.T) {
+	err := newLegacyRepo(nil).Purge(context.Background(), &legacyRecord{ID: 5})
+	require.ErrorIs(t, err, repository.ErrNotSoftDeletable)
+}
+
+func TestPurgeExpired_Batches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cutoff := now.Add(-90 * 24 * time.Hour)
+	selectExpired := regexp.QuoteMeta("SELECT `id` FROM `legacy` WHERE `deleted_at` < ? ORDER BY `id` LIMIT ?")
+
+	mock.ExpectQuery(selectExpired).
+		WithArgs(cutoff, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy_archive`")).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` IN (?, ?) AND `deleted_at` IS NOT NULL")).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery(selectExpired).
+		WithArgs(cutoff, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy_archive`")).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` IN (?) AND `deleted_at` IS NOT NULL")).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := newRetainedRepo(db).PurgeExpired(context.Background(), now, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeExpired_WithoutRetention(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	n, err := newMembershipRepo(db).PurgeExpired(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	n, err = newStatusRepo(db).PurgeExpired(context.Background(), time.Now(), 100)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeAll(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository.RegisterRepository("purge_retained", newRetainedRepo(db))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `legacy` WHERE `deleted_at` < ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	purged, err := repository.PurgeAll(context.Background(), time.Now(), 0)
+	require.NoError(t, err)
+	require.Contains(t, purged, "purge_retained")
+	require.Zero(t, purged["purge_retained"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
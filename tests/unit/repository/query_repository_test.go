@@ -0,0 +1,82 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/not-empty/grit-microframework-go/app/repository/models"
+	"github.com/stretchr/testify/require"
+)
+
+type indexedRecord struct {
+	legacyRecord
+}
+
+func (m *indexedRecord) SortableColumns() []string {
+	return []string{"name"}
+}
+
+func (m *indexedRecord) FilterableColumns() map[string][]string {
+	return map[string][]string{"id": {"eql", "in"}}
+}
+
+func TestSortableColumns(t *testing.T) {
+	require.ElementsMatch(t, []string{"id", "name"}, repository.SortableColumns(&legacyRecord{}))
+	require.Equal(t, []string{"name", "id"}, repository.SortableColumns(&indexedRecord{}))
+}
+
+func TestFilterableColumns(t *testing.T) {
+	require.Equal(t, map[string][]string{"id": nil, "name": nil}, repository.FilterableColumns(&legacyRecord{}))
+	require.Equal(t, map[string][]string{"id": {"eql", "in"}}, repository.FilterableColumns(&indexedRecord{}))
+}
+
+func TestSearchableColumns(t *testing.T) {
+	require.Nil(t, repository.SearchableColumns(&legacyRecord{}))
+	require.Equal(t, []string{"name"}, repository.SearchableColumns(&models.Example{}))
+}
+
+func TestList_SearchByRelevance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	filters := []helper.Filter{helper.SearchFilter([]string{"name"}, "go")}
+	match := "MATCH (`name`) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `name`, "+match+" AS `relevance` FROM `example` "+
+			"WHERE "+match+" AND `deleted_at` IS NULL "+
+			"ORDER BY `relevance` DESC, `id` DESC LIMIT ?",
+	)).
+		WithArgs("go", "go", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "relevance"}).AddRow("2", "Go", 0.5))
+
+	list, err := repo.List(context.Background(), 1, 0, nil, "relevance", "DESC", []string{"id", "name"}, filters)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]any{{"id": "2", "name": "Go", "relevance": 0.5}}, list)
+
+	next, _ := repo.PageCursors(list, 1, nil, "relevance", "DESC", filters)
+	cursor := issuedCursor(t, next)
+	require.Equal(t, []any{0.5, "2"}, cursor.Values)
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `name`, "+match+" AS `relevance` FROM `example` "+
+			"WHERE "+match+" AND `deleted_at` IS NULL "+
+			"AND ( "+match+" < ? OR ( "+match+" = ? AND `id` < ? ) ) "+
+			"ORDER BY `relevance` DESC, `id` DESC LIMIT ?",
+	)).
+		WithArgs("go", "go", "go", 0.5, "go", 0.5, "2", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "relevance"}))
+
+	_, err = repo.List(context.Background(), 1, 0, cursor, "relevance", "DESC", []string{"id", "name"}, filters)
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = repo.List(context.Background(), 1, 0, cursor, "relevance", "DESC", nil, nil)
+	require.ErrorIs(t, err, helper.ErrInvalidCursor)
+}
@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/stretchr/testify/require"
+)
+
+type staffRecord struct {
+	legacyRecord
+	ManagerID *int64 `json:"manager_id"`
+}
+
+func (m *staffRecord) TableName() string {
+	return "staff"
+}
+
+func (m *staffRecord) Schema() map[string]string {
+	return map[string]string{"id": "int", "name": "string", "manager_id": "int"}
+}
+
+func (m *staffRecord) Columns() []string {
+	return []string{"id", "name", "manager_id"}
+}
+
+func (m *staffRecord) Relations() []repository.Relation {
+	return []repository.Relation{
+		repository.BelongsTo("manager", "rel_manager", "manager_id"),
+		repository.HasMany("memberships", "rel_membership", "user_id"),
+	}
+}
+
+func newStaffRepo(db *sql.DB) *repository.Repository[*staffRecord] {
+	repository.RegisterRepository("rel_manager", newLegacyRepo(db))
+	repository.RegisterRepository("rel_membership", newMembershipRepo(db))
+	return repository.NewRepository(db, func() *staffRecord {
+		return &staffRecord{}
+	})
+}
+
+func TestFindRelations(t *testing.T) {
+	rels, err := repository.FindRelations(&staffRecord{}, []string{"memberships"})
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	require.Equal(t, repository.RelationHasMany, rels[0].Kind)
+	require.Equal(t, "id", rels[0].LocalKey)
+
+	_, err = repository.FindRelations(&staffRecord{}, []string{"boss"})
+	require.ErrorIs(t, err, repository.ErrUnknownRelation)
+
+	_, err = repository.FindRelations(&legacyRecord{}, []string{"manager"})
+	require.ErrorIs(t, err, repository.ErrUnknownRelation)
+}
+
+func TestInclude_BelongsToAndHasMany(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `name` FROM `legacy` WHERE `id` IN (?) ORDER BY `id`",
+	)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "boss"))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `role`, `user_id`, `group_id` FROM `membership` WHERE `user_id` IN (?, ?) AND `deleted_at` IS NULL ORDER BY `user_id`, `group_id` LIMIT ?",
+	)).
+		WithArgs(2, 3, repository.DefaultMaxIncludeRows+1).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "user_id", "group_id"}).
+			AddRow("owner", 2, "admins").
+			AddRow("member", 2, "users"))
+
+	records := []map[string]any{
+		{"id": 2, "manager_id": 1},
+		{"id": 3, "manager_id": nil},
+	}
+	err = newStaffRepo(db).Include(context.Background(), records, []string{"manager", "memberships"}, map[string][]string{
+		"manager":     {"id", "name"},
+		"memberships": {"role", "unknown"},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, map[string]any{"id": 1, "name": "boss"}, records[0]["manager"])
+	require.Len(t, records[0]["memberships"], 2)
+	require.Nil(t, records[1]["manager"])
+	require.Equal(t, []map[string]any{}, records[1]["memberships"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInclude_NoKeysSkipsQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	records := []map[string]any{{"id": 2, "manager_id": nil}}
+	require.NoError(t, newStaffRepo(db).Include(context.Background(), records, []string{"manager"}, nil))
+	require.Nil(t, records[0]["manager"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInclude_UnregisteredDomain(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := repository.NewRepository(db, func() *orphanRecord {
+		return &orphanRecord{}
+	})
+	err = repo.Include(context.Background(), []map[string]any{{"id": 1}}, []string{"ghost"}, nil)
+	require.ErrorContains(t, err, `repository "not_registered" is not registered`)
+}
+
+func TestInclude_HasManyTooLarge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository.RegisterRepository("rel_membership_capped", newMembershipRepo(db))
+	repo := repository.NewRepository(db, func() *cappedStaffRecord {
+		return &cappedStaffRecord{}
+	})
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `membership` WHERE `user_id` IN (?) AND `deleted_at` IS NULL ORDER BY `user_id`, `group_id` LIMIT ?")).
+		WithArgs(2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "user_id", "group_id"}).
+			AddRow("owner", 2, "admins").
+			AddRow("member", 2, "users"))
+
+	records := []map[string]any{{"id": 2}}
+	err = repo.Include(context.Background(), records, []string{"memberships"}, nil)
+	require.ErrorIs(t, err, repository.ErrIncludeTooLarge)
+	require.NotContains(t, records[0], "memberships")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type cappedStaffRecord struct {
+	staffRecord
+}
+
+func (m *cappedStaffRecord) Relations() []repository.Relation {
+	memberships := repository.HasMany("memberships", "rel_membership_capped", "user_id")
+	memberships.MaxRows = 1
+	return []repository.Relation{memberships}
+}
+
+type orphanRecord struct {
+	legacyRecord
+}
+
+func (m *orphanRecord) Relations() []repository.Relation {
+	return []repository.Relation{repository.HasMany("ghost", "not_registered", "orphan_id")}
+}
@@ -0,0 +1,189 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	appctx "github.com/not-empty/grit-microframework-go/app/context"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/not-empty/grit-microframework-go/app/repository/models"
+	"github.com/stretchr/testify/require"
+)
+
+func useReplicas(t *testing.T, dbs ...*sql.DB) {
+	repository.SetReadReplicas(dbs...)
+	t.Cleanup(func() {
+		repository.SetReadReplicas()
+		repository.SetReadYourWrites(0)
+	})
+}
+
+func withToken(token string) context.Context {
+	return context.WithValue(context.Background(), appctx.JwtContextKey, appctx.JwtTokenInfo{Token: token})
+}
+
+func expectDetail(mock sqlmock.Sqlmock, id string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `example` WHERE `id` = ?")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+}
+
+func TestReplicas_ReadsGoToReplicaWritesToPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer replica.Close()
+
+	useReplicas(t, replica)
+	repo := newTestRepo(primary)
+
+	replicaMock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `example` WHERE `deleted_at` IS NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	primaryMock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `deleted_at` = NOW()")).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(1) AS total FROM example")).
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(1))
+
+	list, err := repo.List(context.Background(), 10, 0, nil, "id", "asc", []string{"id"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, repo.Delete(context.Background(), &models.Example{ID: "1"}))
+
+	_, err = repo.Raw(context.Background(), "SELECT COUNT(1) AS total FROM example", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, replicaMock.ExpectationsWereMet())
+}
+
+func TestReplicas_RoundRobin(t *testing.T) {
+	first, firstMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer first.Close()
+	second, secondMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer second.Close()
+
+	useReplicas(t, first, second)
+	repo := newTestRepo(nil)
+
+	expectDetail(firstMock, "1")
+	expectDetail(secondMock, "2")
+	expectDetail(firstMock, "3")
+
+	for _, id := range []string{"1", "2", "3"} {
+		_, err := repo.Detail(context.Background(), id, []string{"id"})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, firstMock.ExpectationsWereMet())
+	require.NoError(t, secondMock.ExpectationsWereMet())
+}
+
+func TestReplicas_WithPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer replica.Close()
+
+	useReplicas(t, replica)
+
+	expectDetail(primaryMock, "1")
+
+	_, err = newTestRepo(primary).Detail(repository.WithPrimary(context.Background()), "1", []string{"id"})
+	require.NoError(t, err)
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, replicaMock.ExpectationsWereMet())
+}
+
+func TestReplicas_ReadYourWrites(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer replica.Close()
+
+	useReplicas(t, replica)
+	repository.SetReadYourWrites(time.Minute)
+	repo := newTestRepo(primary)
+
+	primaryMock.ExpectExec(regexp.QuoteMeta("INSERT INTO `example`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectDetail(primaryMock, "1")
+	expectDetail(replicaMock, "1")
+
+	writer := withToken("writer-token")
+	require.NoError(t, repo.Add(writer, &models.Example{ID: "1", Name: "John", Age: 30}))
+
+	_, err = repo.Detail(writer, "1", []string{"id"})
+	require.NoError(t, err)
+
+	_, err = repo.Detail(withToken("other-token"), "1", []string{"id"})
+	require.NoError(t, err)
+
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, replicaMock.ExpectationsWereMet())
+}
+
+func TestReplicas_ReadYourWritesExpires(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer replica.Close()
+
+	useReplicas(t, replica)
+	repository.SetReadYourWrites(10 * time.Millisecond)
+	repo := newTestRepo(primary)
+
+	primaryMock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `name` = ?")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectDetail(replicaMock, "1")
+
+	writer := withToken("writer-token")
+	require.NoError(t, repo.Edit(writer, "example", "id", "1", []string{"name"}, []interface{}{"Jane"}))
+
+	time.Sleep(20 * time.Millisecond)
+
+	_, err = repo.Detail(writer, "1", []string{"id"})
+	require.NoError(t, err)
+
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, replicaMock.ExpectationsWereMet())
+}
+
+func TestReplicas_TransactionStaysOnPrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer primary.Close()
+	replica, replicaMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer replica.Close()
+
+	useReplicas(t, replica)
+	repo := newTestRepo(primary)
+
+	primaryMock.ExpectBegin()
+	expectDetail(primaryMock, "1")
+	primaryMock.ExpectCommit()
+
+	err = repo.RunInTx(context.Background(), func(tx *sql.Tx) error {
+		_, err := repo.WithTx(tx).Detail(context.Background(), "1", []string{"id"})
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, primaryMock.ExpectationsWereMet())
+	require.NoError(t, replicaMock.ExpectationsWereMet())
+}
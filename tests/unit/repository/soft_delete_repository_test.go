@@ -0,0 +1,242 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/not-empty/grit-microframework-go/app/repository/models"
+	"github.com/stretchr/testify/require"
+
+	appctx "github.com/not-empty/grit-microframework-go/app/context"
+)
+
+type statusRecord struct {
+	legacyRecord
+	Status string `json:"status"`
+}
+
+func (m *statusRecord) Schema() map[string]string {
+	return map[string]string{"id": "int", "name": "string", "status": "string"}
+}
+
+func (m *statusRecord) Columns() []string {
+	return []string{"id", "name", "status"}
+}
+
+func (m *statusRecord) SoftDeletePolicy() repository.SoftDeletePolicy {
+	return repository.SoftDeletePolicy{
+		Column:        "status",
+		DeletedBy:     "removed_by",
+		Reason:        "removed_reason",
+		StatusActive:  "active",
+		StatusDeleted: "removed",
+	}
+}
+
+func newStatusRepo(db *sql.DB) *repository.Repository[*statusRecord] {
+	return repository.NewRepository(db, func() *statusRecord {
+		return &statusRecord{}
+	})
+}
+
+func TestHardDelete_Delete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` = ?")).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.False(t, repository.IsSoftDeletable(&legacyRecord{}))
+	require.NoError(t, newLegacyRepo(db).Delete(context.Background(), &legacyRecord{ID: 5}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHardDelete_ReadsWithoutDeletedFilter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newLegacyRepo(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name` FROM `legacy` WHERE `id` = ? LIMIT 1")).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "old"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name` FROM `legacy` ORDER BY `id` DESC LIMIT ?")).
+		WithArgs(25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "old"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `legacy` SET `name` = ? WHERE `id` = ?")).
+		WithArgs("new", int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	row, err := repo.Detail(context.Background(), int64(5), []string{"id", "name"})
+	require.NoError(t, err)
+	require.Equal(t, "old", row["name"])
+
+	list, err := repo.List(context.Background(), 25, 0, nil, "id", "DESC", []string{"id", "name"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	require.NoError(t, repo.Edit(context.Background(), "legacy", "id", int64(5), []string{"name"}, []interface{}{"new"}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHardDelete_DeadEndpointsUnsupported(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newLegacyRepo(db)
+
+	_, err = repo.DeadList(context.Background(), 25, 0, nil, "id", "DESC", nil, nil)
+	require.ErrorIs(t, err, repository.ErrNotSoftDeletable)
+
+	_, err = repo.DeadDetail(context.Background(), int64(5), nil)
+	require.ErrorIs(t, err, repository.ErrNotSoftDeletable)
+
+	err = repo.Undelete(context.Background(), &legacyRecord{ID: 5})
+	require.ErrorIs(t, err, repository.ErrNotSoftDeletable)
+}
+
+func TestStatusColumn_DeleteRecordsActorAndReason(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `legacy` SET `status` = ?, `removed_by` = ?, `removed_reason` = ? WHERE `id` = ? AND `status` <> ?",
+	)).
+		WithArgs("removed", "backoffice", "duplicate", int64(5), "removed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.WithValue(context.Background(), appctx.JwtContextKey, appctx.JwtTokenInfo{Context: "backoffice"})
+	ctx = repository.WithDeleteReason(ctx, "duplicate")
+
+	m := &statusRecord{legacyRecord: legacyRecord{ID: 5}}
+	require.NoError(t, newStatusRepo(db).Delete(ctx, m))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusColumn_UndeleteAndDeadList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newStatusRepo(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `legacy` SET `status` = ?, `removed_by` = NULL, `removed_reason` = NULL WHERE `id` = ? AND `status` = ?",
+	)).
+		WithArgs("active", int64(5), "removed").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		"SELECT `id`, `status` FROM `legacy` WHERE `status` = ? ORDER BY `id` DESC LIMIT ?",
+	)).
+		WithArgs("removed", 25).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(6, "removed"))
+
+	require.NoError(t, repo.Undelete(context.Background(), &statusRecord{legacyRecord: legacyRecord{ID: 5}}))
+
+	list, err := repo.DeadList(context.Background(), 25, 0, nil, "id", "DESC", []string{"id", "status"}, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSoftDelete_DefaultsToDeletedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		"UPDATE `membership` SET `deleted_at` = NOW() WHERE `user_id` = ? AND `group_id` = ? AND `deleted_at` IS NULL",
+	)).
+		WithArgs(int64(7), "admins").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.True(t, repository.IsSoftDeletable(&membership{}))
+	require.NoError(t, newMembershipRepo(db).Delete(context.Background(), &membership{UserID: 7, GroupID: "admins"}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNoRows_DeleteStates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	softDelete := regexp.QuoteMeta("UPDATE `example` SET `deleted_at` = NOW() WHERE `id` = ? AND `deleted_at` IS NULL")
+	deadLookup := regexp.QuoteMeta("SELECT `id` FROM `example` WHERE `id` = ? AND `deleted_at` IS NOT NULL LIMIT 1")
+
+	mock.ExpectExec(softDelete).WithArgs("gone").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(deadLookup).WithArgs("gone").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("gone"))
+	mock.ExpectExec(softDelete).WithArgs("missing").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(deadLookup).WithArgs("missing").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err = repo.Delete(context.Background(), &models.Example{ID: "gone"})
+	require.ErrorIs(t, err, repository.ErrAlreadyDeleted)
+
+	err = repo.Delete(context.Background(), &models.Example{ID: "missing"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNoRows_HardDeleteMissing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `legacy` WHERE `id` = ?")).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = newLegacyRepo(db).Delete(context.Background(), &legacyRecord{ID: 5})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNoRows_UndeleteActiveRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `deleted_at` = NULL WHERE `id` = ? AND `deleted_at` IS NOT NULL")).
+		WithArgs("alive").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1")).
+		WithArgs("alive").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("alive"))
+
+	err = newTestRepo(db).Undelete(context.Background(), &models.Example{ID: "alive"})
+	require.ErrorIs(t, err, repository.ErrNotDeleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNoRows_EditStates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+	update := regexp.QuoteMeta("UPDATE `example` SET `name` = ? WHERE `id` = ? AND `deleted_at` IS NULL")
+	aliveLookup := regexp.QuoteMeta("SELECT `id` FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1")
+	deadLookup := regexp.QuoteMeta("SELECT `id` FROM `example` WHERE `id` = ? AND `deleted_at` IS NOT NULL LIMIT 1")
+
+	mock.ExpectExec(update).WithArgs("same", "unchanged").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(aliveLookup).WithArgs("unchanged").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("unchanged"))
+	mock.ExpectExec(update).WithArgs("same", "gone").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(aliveLookup).WithArgs("gone").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(deadLookup).WithArgs("gone").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("gone"))
+
+	err = repo.Edit(context.Background(), "`example`", "id", "unchanged", []string{"name"}, []interface{}{"same"})
+	require.NoError(t, err)
+
+	err = repo.Edit(context.Background(), "`example`", "id", "gone", []string{"name"}, []interface{}{"same"})
+	require.ErrorIs(t, err, repository.ErrAlreadyDeleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
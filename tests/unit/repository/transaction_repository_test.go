@@ -0,0 +1,341 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/not-empty/grit-microframework-go/app/repository/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInTx_Commit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `example`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `deleted_at` = NOW()")).
+		WithArgs("2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.RunInTx(context.Background(), func(tx *sql.Tx) error {
+		txRepo := repo.WithTx(tx)
+		if err := txRepo.Add(context.Background(), &models.Example{ID: "1", Name: "John", Age: 30}); err != nil {
+			return err
+		}
+		return txRepo.Delete(context.Background(), &models.Example{ID: "2"})
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTx_RollbackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `example`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	boom := errors.New("business rule failed")
+	err = repository.RunInTx(context.Background(), db, func(tx *sql.Tx) error {
+		if err := repo.WithTx(tx).Add(context.Background(), &models.Example{ID: "1", Name: "John", Age: 30}); err != nil {
+			return err
+		}
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTx_RollbackOnPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	require.PanicsWithValue(t, "boom", func() {
+		_ = repository.RunInTx(context.Background(), db, func(tx *sql.Tx) error {
+			panic("boom")
+		})
+	})
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTx_BeginError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin().WillReturnError(errors.New("begin failed"))
+
+	called := false
+	err = repository.RunInTx(context.Background(), db, func(tx *sql.Tx) error {
+		called = true
+		return nil
+	})
+	require.EqualError(t, err, "begin failed")
+	require.False(t, called)
+}
+
+func TestRepositoryRunInTx_ReusesBoundTx(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	err = repo.RunInTx(context.Background(), func(tx *sql.Tx) error {
+		return repo.WithTx(tx).RunInTx(context.Background(), func(inner *sql.Tx) error {
+			require.Same(t, tx, inner)
+			return nil
+		})
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDetailForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name` FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1 FOR UPDATE")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("1", "John"))
+	mock.ExpectCommit()
+
+	err = repo.RunInTx(context.Background(), func(tx *sql.Tx) error {
+		row, err := repo.WithTx(tx).DetailForUpdate(context.Background(), "1", []string{"id", "name"})
+		require.Equal(t, "John", row["name"])
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDetailForUpdate_SQLiteSkipsLock(t *testing.T) {
+	helper.SetDialect(helper.SQLiteDialect{})
+	t.Cleanup(func() { helper.SetDialect(nil) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := newTestRepo(db)
+
+	mock.ExpectQuery(`LIMIT 1$`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+
+	_, err = repo.DetailForUpdate(context.Background(), "1", []string{"id"})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTxRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repository.RegisterRepository("tx_example", newTestRepo(db))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `deleted_at` = NULL")).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repository.RunInTx(context.Background(), db, func(tx *sql.Tx) error {
+		txRepo, err := repository.TxRepository[*models.Example](tx, "tx_example")
+		if err != nil {
+			return err
+		}
+		return txRepo.Undelete(context.Background(), &models.Example{ID: "1"})
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+type otherModel struct{ models.Example }
+
+func TestGetRepository_Errors(t *testing.T) {
+	_, err := repository.GetRepository[*models.Example]("missing")
+	require.EqualError(t, err, `repository "missing" is not registered`)
+
+	repository.RegisterRepository("tx_example_typed", newTestRepo(nil))
+	_, err = repository.GetRepository[*otherModel]("tx_example_typed")
+	require.EqualError(t, err, `repository "tx_example_typed" is registered for another model`)
+}
+
+type versionedExample struct {
+	models.Example
+	Version int `json:"version"`
+}
+
+func (m *versionedExample) VersionColumn() string {
+	return "version"
+}
+
+func (m *versionedExample) Schema() map[string]string {
+	schema := m.Example.Schema()
+	schema["version"] = "int"
+	return schema
+}
+
+func newVersionedRepo(db *sql.DB) *repository.Repository[*versionedExample] {
+	return repository.NewRepository(db, func() *versionedExample {
+		return &versionedExample{}
+	})
+}
+
+func TestVersionColumn(t *testing.T) {
+	require.Equal(t, "version", repository.VersionColumn(&versionedExample{}))
+	require.Equal(t, "updated_at", repository.VersionColumn(&models.Example{}))
+}
+
+func TestEdit_BumpsVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `name` = ?, `version` = `version` + 1 WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("Jane", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = newVersionedRepo(db).Edit(context.Background(), "example", "id", "1", []string{"name"}, []interface{}{"Jane"})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func exampleRows(name string) *sqlmock.Rows {
+	at := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	return sqlmock.NewRows([]string{"id", "name", "age", "last_seen", "last_login", "created_at", "updated_at", "deleted_at"}).
+		AddRow("1", name, 30, nil, nil, at, at, nil)
+}
+
+func TestEditIfMatch_UpdatedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(exampleRows("John"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name`, `age`, `last_seen`, `last_login`, `created_at`, `updated_at`, `deleted_at` FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1 FOR UPDATE")).
+		WithArgs("1").
+		WillReturnRows(exampleRows("John"))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `name` = ? WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("Jane", "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := newTestRepo(db)
+	record, err := repo.Detail(context.Background(), "1", nil)
+	require.NoError(t, err)
+
+	err = repo.EditIfMatch(context.Background(), "example", "id", "1", []string{"name"}, []interface{}{"Jane"}, repository.RecordETag(&models.Example{}, record))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditIfMatch_UpdatedAtSameSecond(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1")).
+		WithArgs("1").
+		WillReturnRows(exampleRows("John"))
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE")).
+		WithArgs("1").
+		WillReturnRows(exampleRows("Jack"))
+	mock.ExpectRollback()
+
+	repo := newTestRepo(db)
+	record, err := repo.Detail(context.Background(), "1", nil)
+	require.NoError(t, err)
+
+	err = repo.EditIfMatch(context.Background(), "example", "id", "1", []string{"name"}, []interface{}{"Jane"}, repository.RecordETag(&models.Example{}, record))
+	require.ErrorIs(t, err, repository.ErrPreconditionFailed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEditIfMatch_Stale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `version` FROM `example`")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectRollback()
+
+	err = newVersionedRepo(db).EditIfMatch(context.Background(), "example", "id", "1", []string{"name"}, []interface{}{"Jane"}, helper.ETag(3))
+	require.ErrorIs(t, err, repository.ErrPreconditionFailed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteIfMatch_Versioned(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `version` FROM `example` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1 FOR UPDATE")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `example` SET `deleted_at` = NOW(), `version` = `version` + 1 WHERE `id` = ? AND `deleted_at` IS NULL")).
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m := &versionedExample{}
+	m.ID = "1"
+	err = newVersionedRepo(db).DeleteIfMatch(context.Background(), m, helper.ETag(3))
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUndeleteIfMatch_MissingRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `example` WHERE `id` = ? AND `deleted_at` IS NOT NULL LIMIT 1 FOR UPDATE")).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	err = newTestRepo(db).UndeleteIfMatch(context.Background(), &models.Example{ID: "1"}, "*")
+	require.ErrorIs(t, err, repository.ErrPreconditionFailed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
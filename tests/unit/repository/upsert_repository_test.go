@@ -0,0 +1,139 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/not-empty/grit-microframework-go/app/helper"
+	"github.com/not-empty/grit-microframework-go/app/repository"
+	"github.com/not-empty/grit-microframework-go/app/repository/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsert_MySQL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	m := &models.Example{ID: "01", Name: "Alice", Age: 30, CreatedAt: &now, UpdatedAt: &now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `deleted_at` FROM `example` WHERE `id` IN (?) FOR UPDATE")).
+		WithArgs("01").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow("01", nil))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `example` (`id`, `name`, `age`, `created_at`, `updated_at`, `deleted_at`) VALUES (?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `age` = VALUES(`age`), `updated_at` = VALUES(`updated_at`)",
+	)).
+		WithArgs("01", "Alice", 30, &now, &now, nil).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	created, err := newTestRepo(db).Upsert(context.Background(), m)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsert_RefusesSoftDeletedKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &models.Example{ID: "01", Name: "Alice", Age: 30}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `deleted_at` FROM `example` WHERE `id` IN (?) FOR UPDATE")).
+		WithArgs("01").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow("01", time.Now()))
+	mock.ExpectRollback()
+
+	_, err = newTestRepo(db).Upsert(context.Background(), m)
+	require.ErrorIs(t, err, repository.ErrAlreadyDeleted)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsert_PostgresBumpsVersion(t *testing.T) {
+	helper.SetDialect(helper.PostgresDialect{})
+	t.Cleanup(func() { helper.SetDialect(helper.MySQLDialect{}) })
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	m := &versionedExample{Example: models.Example{ID: "01", Name: "Alice", Age: 30}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id", "deleted_at" FROM "example" WHERE "id" IN ($1) FOR UPDATE`)).
+		WithArgs("01").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "example" ("id", "name", "age", "created_at", "updated_at", "deleted_at") VALUES ($1, $2, $3, $4, $5, $6) ` +
+			`ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name", "age" = EXCLUDED."age", "updated_at" = EXCLUDED."updated_at", ` +
+			`"version" = "example"."version" + 1`,
+	)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err := newVersionedRepo(db).Upsert(context.Background(), m)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkUpsert_GroupsRowsByShape(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	seen := &helper.JSONTime{}
+	items := []*models.Example{
+		{ID: "01", Name: "Alice", Age: 30},
+		{ID: "02", Name: "Bobby", Age: 40, LastSeen: seen},
+		{ID: "03", Name: "Carol", Age: 50},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `deleted_at` FROM `example` WHERE `id` IN (?, ?, ?) FOR UPDATE")).
+		WithArgs("01", "02", "03").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow("02", nil))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `example` (`id`, `name`, `age`, `created_at`, `updated_at`, `deleted_at`) VALUES (?, ?, ?, ?, ?, ?), (?, ?, ?, ?, ?, ?) " +
+			"ON DUPLICATE KEY UPDATE",
+	)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(
+		"INSERT INTO `example` (`id`, `name`, `age`, `last_seen`, `created_at`, `updated_at`, `deleted_at`) VALUES (?, ?, ?, ?, ?, ?, ?) " +
+			"ON DUPLICATE KEY UPDATE `name` = VALUES(`name`), `age` = VALUES(`age`), `last_seen` = VALUES(`last_seen`), `updated_at` = VALUES(`updated_at`)",
+	)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err := newTestRepo(db).BulkUpsert(context.Background(), items)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false, true}, created)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsert_AutoIncrementWithoutID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `legacy` (`name`) VALUES (?)")).
+		WithArgs("new").
+		WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectCommit()
+
+	m := &legacyRecord{Name: "new"}
+	created, err := newLegacyRepo(db).Upsert(context.Background(), m)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, int64(12), m.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}